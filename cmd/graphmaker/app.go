@@ -7,6 +7,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/gsmcwhirter/eve-route-finder/pkg/system"
@@ -21,6 +23,8 @@ type App struct {
 	sourceDir string
 	outFile   string
 
+	highTraffic int
+
 	ctx         context.Context
 	cancel      context.CancelFunc
 	workers     *errgroup.Group
@@ -90,6 +94,26 @@ func (a *App) Run() error {
 			}
 		}
 		a.SystemData[i].Destinations = realDest
+
+		realGates := make([]system.Gate, 0, len(a.SystemData[i].Gates))
+		for _, g := range a.SystemData[i].Gates {
+			ds, ok := a.systemGatesData[strconv.FormatInt(g.DestinationGate, 10)]
+			if !ok {
+				continue
+			}
+
+			g.Destination = ds
+			g.Name = fmt.Sprintf("Stargate (%s)", ds)
+			realGates = append(realGates, g)
+		}
+		sort.Slice(realGates, func(j, k int) bool { return realGates[j].ID < realGates[k].ID })
+		a.SystemData[i].Gates = realGates
+	}
+
+	if a.highTraffic > 0 {
+		if err := a.TagHighTraffic(a.highTraffic); err != nil {
+			return err
+		}
 	}
 
 	f, err := os.Create(a.outFile)
@@ -284,12 +308,32 @@ func (a *App) ParseSystemData(path string) func() error {
 		}
 
 		destinations := make([]string, 0, len(rawInfo.Stargates))
+		gates := make([]system.Gate, 0, len(rawInfo.Stargates))
 
 		if _, ok := a.trigFinal[systemName]; !ok {
 			// we're not final-lim, so we have gates
 			for k, v := range rawInfo.Stargates {
 				destinations = append(destinations, v.Destination)
 
+				// destination names are filled in once every system is parsed
+				gateID, err := strconv.ParseInt(k, 10, 64)
+				if err != nil {
+					return errors.Wrap(ErrParseFailed, "bad stargate id", "path", path, "gate", k)
+				}
+				destGateID, err := strconv.ParseInt(v.Destination, 10, 64)
+				if err != nil {
+					return errors.Wrap(ErrParseFailed, "bad stargate destination", "path", path, "gate", k)
+				}
+				gate := system.Gate{ID: gateID, DestinationGate: destGateID}
+				if len(v.Position) == 3 {
+					gate.Position = &system.Position{
+						X: v.Position[0],
+						Y: v.Position[1],
+						Z: v.Position[2],
+					}
+				}
+				gates = append(gates, gate)
+
 				a.systemGates <- SystemGate{
 					System: systemName,
 					GateID: k,
@@ -302,10 +346,19 @@ func (a *App) ParseSystemData(path string) func() error {
 			Constellation: constellation,
 			Region:        region,
 			Destinations:  destinations,
+			Gates:         gates,
 			SecStatus:     secStatus,
 			Tags:          tags,
 		}
 
+		if len(rawInfo.Center) == 3 {
+			systemData.Position = &system.Position{
+				X: rawInfo.Center[0],
+				Y: rawInfo.Center[1],
+				Z: rawInfo.Center[2],
+			}
+		}
+
 		fmt.Printf("%s: %#v\n", reldir, systemData)
 
 		a.parsed <- systemData
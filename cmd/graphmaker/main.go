@@ -13,6 +13,7 @@ func run() error {
 	pflag.StringVarP(&app.sourceDir, "source-dir", "s", "", "eve source file directory")
 	pflag.StringVarP(&app.dataDir, "data-dir", "d", "", "local data directory")
 	pflag.StringVarP(&app.outFile, "out-file", "o", "", "output file")
+	pflag.IntVar(&app.highTraffic, "high-traffic", 0, "tag this many of the busiest systems high-traffic (0 for none)")
 
 	pflag.Parse()
 
@@ -1,10 +1,12 @@
 package main
 
 type RawSystemData struct {
+	Center    []float64           `yaml:"center"`
 	Security  float64             `yaml:"security"`
 	Stargates map[string]Stargate `yaml:"stargates"`
 }
 
 type Stargate struct {
-	Destination string `yaml:"destination"`
+	Destination string    `yaml:"destination"`
+	Position    []float64 `yaml:"position"`
 }
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"github.com/gsmcwhirter/eve-route-finder/pkg/path"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+)
+
+const highTrafficTag = "high-traffic"
+
+// TagHighTraffic adds the high-traffic tag to the n systems with the most
+// shortest routes through them.
+func (a *App) TagHighTraffic(n int) error {
+	ids := make(map[string]int, len(a.SystemData))
+	for i, sd := range a.SystemData {
+		ids[sd.Name] = i
+	}
+
+	graph := make([][]int, len(a.SystemData))
+	secStatus := make([]string, len(a.SystemData))
+	for i, sd := range a.SystemData {
+		secStatus[i] = sd.SecStatus
+		for _, d := range sd.Destinations {
+			if to, ok := ids[d]; ok {
+				graph[i] = append(graph[i], to)
+			}
+		}
+	}
+
+	finder := path.NewFinder(graph, nil, secStatus, nil)
+	ranked, err := finder.RankCentrality(a.ctx, path.CentralityQuery{Limit: n})
+	if err != nil {
+		return errors.Wrap(err, "could not rank systems by traffic")
+	}
+
+	for _, c := range ranked {
+		if c.Score > 0 {
+			a.SystemData[c.System].Tags = append(a.SystemData[c.System].Tags, highTrafficTag)
+		}
+	}
+
+	return nil
+}
@@ -1,115 +1,447 @@
 package main
 
 import (
+	"context"
+	stderr "errors"
 	"fmt"
-	"os"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/gsmcwhirter/eve-route-finder/pkg/danger"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/jump"
 	"github.com/gsmcwhirter/eve-route-finder/pkg/path"
-	"github.com/gsmcwhirter/eve-route-finder/pkg/system"
-	"github.com/gsmcwhirter/go-util/v7/deferutil"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/profile"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/tagexpr"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/universe"
 	"github.com/pkg/errors"
-	"gopkg.in/yaml.v3"
 )
 
 type App struct {
 	systemDataFile string
 
-	fromSystems   []string
-	toSystem      string
-	toTag         string
-	avoidSystems  []string
-	avoidTags     []string
-	preferNotTags []string
+	fromSystems    []string
+	toSystem       string
+	toTag          string
+	toExpr         string
+	avoidSystems   []string
+	avoidGates     []string
+	showGates      bool
+	avoidTags      []string
+	avoidExprs     []string
+	preferNotTags  []string
+	preferNotExprs []string
+	maxJumps       int
+	maxTagJumps    map[string]int
+	priorities     []string
+	pareto         []string
+
+	killFile    string
+	dangerModel danger.Model
+
+	bridgeFile   string
+	useBridges   bool
+	bridgeOwners []string
+	bridgeCost   int
+
+	jumpDrive      bool
+	shipClass      string
+	jumpRangeLY    float64
+	jumpRangeFlags map[string]string
+	objective      string
+	jumpObjective  string
+	travel         path.Travel
+	jumpSkills     jump.Skills
+	jumpFatigue    time.Duration
+
+	profileFile string
+	profileName string
+
+	limit   int
+	meetBy  string
+	weights map[string]int
+
+	targets        []string
+	stagingRange   int
+	candidateTags  []string
+	candidateExprs []string
+
+	roamSystems int
+	preferTags  []string
+	preferExprs []string
+	seed        int64
+
+	returnHome bool
+
+	contractFile string
+	highSecOnly  bool
+
+	closeSystems []string
+	closeGates   []string
+	hubs         []string
+	hubTags      []string
+	hubExprs     []string
+	sampleSize   int
+
+	withinTags  []string
+	withinExprs []string
+
+	logDir       string
+	channels     []string
+	fromStart    bool
+	warnJumps    int
+	pollInterval time.Duration
+	reportTo     string
+	reportTTL    float64
+
+	universe   *universe.Universe
+	pathfinder *path.Finder
+}
 
-	rawDataContents DataContents
+func NewApp() *App {
+	return &App{}
+}
 
-	tags           map[string]int
-	reverseTags    map[int]string
-	systems        map[string]int
-	reverseSystems map[int]string
+func (a *App) Run() error {
+	if a.jumpDrive {
+		return a.RunJumpDrive()
+	}
 
-	systemSec map[int]string
+	numTargets := 0
+	for _, t := range []string{a.toSystem, a.toTag, a.toExpr} {
+		if t != "" {
+			numTargets++
+		}
+	}
 
-	pathfinder *path.Finder
+	if numTargets > 1 {
+		return errors.New("cannot provide more than one of target system, tag, and expression")
+	}
+
+	if numTargets == 0 {
+		return errors.New("must provide either target system, tag, or expression")
+	}
+
+	if err := a.prepare(); err != nil {
+		return err
+	}
+
+	q, err := a.query()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case a.toSystem != "":
+		fmt.Printf("from: %v, to: %s, avoid: %v, avoid tags: %v, soft avoid tags: %v\n", a.fromSystems, a.toSystem, a.avoidSystems, a.avoidTags, a.preferNotTags)
+	case a.toTag != "":
+		fmt.Printf("from: %v, to tag: %s, avoid: %v, avoid tags: %v, soft avoid tags: %v\n", a.fromSystems, a.toTag, a.avoidSystems, a.avoidTags, a.preferNotTags)
+	case a.toExpr != "":
+		fmt.Printf("from: %v, to expr: %s, avoid: %v, avoid tags: %v %v, soft avoid tags: %v %v\n", a.fromSystems, a.toExpr, a.avoidSystems, a.avoidTags, a.avoidExprs, a.preferNotTags, a.preferNotExprs)
+	}
+
+	if len(a.pareto) > 0 {
+		return a.runPareto(q)
+	}
+
+	var routes []path.Route
+	if a.limit > 0 && a.toSystem == "" {
+		routes, err = a.pathfinder.NearestRoutes(context.Background(), q)
+	} else {
+		routes, err = a.pathfinder.Route(context.Background(), q)
+	}
+
+	var noRoute *path.NoRouteError
+	if stderr.As(err, &noRoute) && noRoute.Cut != nil {
+		fmt.Printf("blocked by: systems %v, tags %v %v (%d jumps without them)\n", a.universe.SystemNames(noRoute.Cut.Avoids), a.universe.TagNames(noRoute.Cut.AvoidTags), universe.ExprNames(noRoute.Cut.AvoidExprs), noRoute.Cut.Jumps)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "could not find a viable route")
+	}
+
+	for _, route := range routes {
+		fmt.Println(a.GetNiceRoute(route.Systems))
+		fmt.Println(a.GetRouteStats(route))
+
+		if a.showGates {
+			for _, e := range route.Edges {
+				fmt.Printf("    %s -> %s via %s\n", a.universe.SystemName(e.From), a.universe.SystemName(e.To), a.edgeName(e))
+			}
+		}
+	}
+
+	return nil
 }
 
-func NewApp() *App {
-	return &App{
-		tags:           map[string]int{},
-		reverseTags:    map[int]string{},
-		systems:        map[string]int{},
-		reverseSystems: map[int]string{},
-		systemSec:      map[int]string{},
+// applyProfile fills in the options not given on the command line from the
+// named ship profile, and adds its tags and expressions to those given.
+func (a *App) applyProfile(changed func(flag string) bool) error {
+	if a.profileName == "" {
+		return nil
+	}
+
+	if a.profileFile == "" {
+		return errors.New("must provide a profile file to use a profile")
+	}
+
+	profiles, err := profile.Load(a.profileFile)
+	if err != nil {
+		return errors.Wrap(err, "could not load ship profiles")
+	}
+
+	p, err := profiles.Get(a.profileName)
+	if err != nil {
+		return err
+	}
+
+	a.avoidTags = append(a.avoidTags, p.AvoidTags...)
+	a.avoidExprs = append(a.avoidExprs, p.AvoidExprs...)
+	a.preferNotTags = append(a.preferNotTags, p.PreferNotTags...)
+	a.preferNotExprs = append(a.preferNotExprs, p.PreferNotExprs...)
+
+	for tag, max := range p.MaxTagJumps {
+		if _, ok := a.maxTagJumps[tag]; ok {
+			continue
+		}
+
+		if a.maxTagJumps == nil {
+			a.maxTagJumps = map[string]int{}
+		}
+		a.maxTagJumps[tag] = max
+	}
+
+	if p.UseBridges && !changed("use-bridges") {
+		a.useBridges = true
+	}
+	if p.BridgeCost != 0 && !changed("bridge-cost") {
+		a.bridgeCost = p.BridgeCost
+	}
+	if p.Objective != "" && !a.jumpDrive && !changed("objective") {
+		a.objective = p.Objective
+	}
+	if p.ShipClass != "" && !changed("ship-class") {
+		a.shipClass = p.ShipClass
 	}
+	if p.JumpRangeLY != 0 && !changed("jump-range-ly") {
+		a.jumpRangeLY = p.JumpRangeLY
+	}
+
+	travel := p.Travel(a.travel)
+	if !changed("align-time") {
+		a.travel.AlignTime = travel.AlignTime
+	}
+	if !changed("warp-speed") {
+		a.travel.WarpSpeed = travel.WarpSpeed
+	}
+	if !changed("jump-time") {
+		a.travel.JumpTime = travel.JumpTime
+	}
+	if !changed("gate-cloak") {
+		a.travel.GateCloak = travel.GateCloak
+	}
+
+	return nil
 }
 
-type DataContents struct {
-	SystemData []system.Data
+// constraints returns the avoid lists, bridge options, and travel model from
+// the command line.
+func (a *App) constraints() (path.Constraints, error) {
+	q, err := a.query()
+	if err != nil {
+		return path.Constraints{}, err
+	}
+
+	return path.Constraints{
+		Avoids:       q.Avoids,
+		AvoidGates:   q.AvoidGates,
+		AvoidTags:    q.AvoidTags,
+		AvoidExprs:   q.AvoidExprs,
+		UseBridges:   q.UseBridges,
+		BridgeOwners: q.BridgeOwners,
+		Travel:       q.Travel,
+	}, nil
 }
 
-func (a *App) Run() error {
-	if a.toSystem != "" && a.toTag != "" {
-		return errors.New("cannot provide both target system and tag")
+// prepare loads the universe and sets up the path finder.
+func (a *App) prepare() error {
+	u, err := universe.Load(a.systemDataFile)
+	if err != nil {
+		return errors.Wrap(err, "could not load system data")
 	}
+	a.universe = u
+	a.pathfinder = u.Finder
 
-	if a.toSystem == "" && a.toTag == "" {
-		return errors.New("must provide either target system or tag")
+	if a.bridgeFile != "" {
+		if err := u.LoadBridges(a.bridgeFile); err != nil {
+			return errors.Wrap(err, "could not load jump bridges")
+		}
 	}
 
-	if err := a.loadSystemData(); err != nil {
-		return errors.Wrap(err, "could not load system data")
+	return nil
+}
+
+// query builds a route query from the command line flags.
+func (a *App) query() (path.Query, error) {
+	fromIDs, err := a.universe.SystemIDs(a.fromSystems)
+	if err != nil {
+		return path.Query{}, errors.Wrap(err, "bad source systems")
+	}
+	avoidIDs, err := a.universe.SystemIDs(a.avoidSystems)
+	if err != nil {
+		return path.Query{}, errors.Wrap(err, "bad avoid systems")
+	}
+	avoidTagIDs, err := a.universe.TagIDs(a.avoidTags)
+	if err != nil {
+		return path.Query{}, errors.Wrap(err, "bad avoid tags")
+	}
+	preferNotTagIDs, err := a.universe.TagIDs(a.preferNotTags)
+	if err != nil {
+		return path.Query{}, errors.Wrap(err, "bad prefer-not tags")
 	}
 
-	if err := a.preparePathFinder(); err != nil {
-		return errors.Wrap(err, "could not populate data")
+	avoidExprs, err := a.universe.ParseExprs(a.avoidExprs)
+	if err != nil {
+		return path.Query{}, errors.Wrap(err, "bad avoid expression")
+	}
+	preferNotExprs, err := a.universe.ParseExprs(a.preferNotExprs)
+	if err != nil {
+		return path.Query{}, errors.Wrap(err, "bad prefer-not expression")
 	}
 
-	fromIDs := make([]int, len(a.fromSystems))
-	for i, sname := range a.fromSystems {
-		fromIDs[i] = a.systems[sname]
+	budgets, err := a.universe.Budgets(a.maxTagJumps)
+	if err != nil {
+		return path.Query{}, errors.Wrap(err, "bad max tag jumps")
 	}
-	avoidIDs := make([]int, len(a.avoidSystems))
-	for i, aname := range a.avoidSystems {
-		avoidIDs[i] = a.systems[aname]
+
+	avoidGates, err := a.universe.GateIDs(a.avoidGates)
+	if err != nil {
+		return path.Query{}, errors.Wrap(err, "bad avoid gates")
 	}
-	avoidTagIDs := make([]int, len(a.avoidTags))
-	for i, tname := range a.avoidTags {
-		avoidTagIDs[i] = a.tags[tname]
+
+	objective, err := path.ParseRouteObjective(a.objective)
+	if err != nil {
+		return path.Query{}, err
 	}
-	preferNotTagIDs := make([]int, len(a.preferNotTags))
-	for i, tname := range a.preferNotTags {
-		preferNotTagIDs[i] = a.tags[tname]
+
+	priorities, err := a.parsePriorities(a.priorities)
+	if err != nil {
+		return path.Query{}, errors.Wrap(err, "bad priorities")
 	}
 
-	var routes [][]int
-	var err error
+	travel := a.travel
+
+	q := path.Query{
+		Sources:        fromIDs,
+		Avoids:         avoidIDs,
+		AvoidGates:     avoidGates,
+		AvoidTags:      avoidTagIDs,
+		AvoidExprs:     avoidExprs,
+		PreferNotTags:  preferNotTagIDs,
+		PreferNotExprs: preferNotExprs,
+		MaxJumps:       a.maxJumps,
+		Budgets:        budgets,
+		Limit:          a.limit,
+		UseBridges:     a.useBridges,
+		BridgeOwners:   a.bridgeOwners,
+		BridgeCost:     a.bridgeCost,
+		Priorities:     priorities,
+		Objective:      objective,
+		Travel:         &travel,
+	}
 
 	switch {
 	case a.toSystem != "":
-		fmt.Printf("from: %v, to: %s, avoid: %v, avoid tags: %v, soft avoid tags: %v\n", a.fromSystems, a.toSystem, a.avoidSystems, a.avoidTags, a.preferNotTags)
-		toID := a.systems[a.toSystem]
-		if len(fromIDs) == 1 {
-			routes, err = a.pathfinder.FindShortestRoutes(fromIDs[0], toID, avoidIDs, avoidTagIDs, preferNotTagIDs)
-		} else {
-			routes, err = a.pathfinder.FindAllShortestRoutes(fromIDs, toID, avoidIDs, avoidTagIDs, preferNotTagIDs)
+		q.Targets, err = a.universe.SystemIDs([]string{a.toSystem})
+		if err != nil {
+			return q, errors.Wrap(err, "bad target system")
 		}
 	case a.toTag != "":
-		fmt.Printf("from: %v, to tag: %s, avoid: %v, avoid tags: %v, soft avoid tags: %v\n", a.fromSystems, a.toSystem, a.avoidSystems, a.avoidTags, a.preferNotTags)
-		toID := a.tags[a.toTag]
-		if len(fromIDs) == 1 {
-			routes, err = a.pathfinder.FindShortestRoutesToTag(fromIDs[0], toID, avoidIDs, avoidTagIDs, preferNotTagIDs)
-		} else {
-			routes, err = a.pathfinder.FindAllShortestRoutesToTag(fromIDs, toID, avoidIDs, avoidTagIDs, preferNotTagIDs)
+		q.TargetTags, err = a.universe.TagIDs([]string{a.toTag})
+		if err != nil {
+			return q, errors.Wrap(err, "bad target tag")
+		}
+	case a.toExpr != "":
+		q.TargetExprs, err = a.universe.ParseExprs([]string{a.toExpr})
+		if err != nil {
+			return q, errors.Wrap(err, "bad target expression")
 		}
 	}
 
+	return q, nil
+}
+
+func (a *App) RunJumpDrive() error {
+	if a.toSystem == "" {
+		return errors.New("must provide a target system")
+	}
+
+	if err := a.prepare(); err != nil {
+		return err
+	}
+
+	rangeLY := a.jumpRangeLY
+	if rangeLY == 0 {
+		jumpRanges, err := jump.NewRanges(a.jumpRangeFlags)
+		if err != nil {
+			return errors.Wrap(err, "could not set jump ranges")
+		}
+
+		if rangeLY, err = jumpRanges.Range(a.shipClass); err != nil {
+			return errors.Wrapf(err, "could not determine jump range (valid classes: %v)", jumpRanges.Classes())
+		}
+	}
+
+	objective, err := path.ParseJumpObjective(a.jumpObjective)
 	if err != nil {
-		return errors.Wrap(err, "could not find a viable route")
+		return err
 	}
 
-	for _, route := range routes {
-		fmt.Println(a.GetNiceRoute(route))
+	hull, err := jump.HullFor(a.shipClass)
+	if err != nil {
+		return errors.Wrap(err, "could not determine hull")
 	}
+	calc := jump.NewCalculator(hull, a.jumpSkills)
+
+	fromIDs, err := a.universe.SystemIDs(a.fromSystems)
+	if err != nil {
+		return errors.Wrap(err, "bad source systems")
+	}
+	avoidIDs, err := a.universe.SystemIDs(a.avoidSystems)
+	if err != nil {
+		return errors.Wrap(err, "bad avoid systems")
+	}
+	avoidTagIDs, err := a.universe.TagIDs(a.avoidTags)
+	if err != nil {
+		return errors.Wrap(err, "bad avoid tags")
+	}
+	toIDs, err := a.universe.SystemIDs([]string{a.toSystem})
+	if err != nil {
+		return errors.Wrap(err, "bad target system")
+	}
+
+	fmt.Printf("jump from: %v, to: %s, range: %.2f ly, avoid: %v, avoid tags: %v\n", a.fromSystems, a.toSystem, rangeLY, a.avoidSystems, a.avoidTags)
+
+	route, err := a.pathfinder.FindJumpRoute(context.Background(), path.JumpQuery{
+		Sources:          fromIDs,
+		Targets:          toIDs,
+		RangeLY:          rangeLY,
+		Avoids:           avoidIDs,
+		AvoidTags:        avoidTagIDs,
+		Objective:        objective,
+		FatigueReduction: hull.FatigueReduction,
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not find a viable jump route")
+	}
+
+	plan := calc.Plan(route.Legs, a.jumpFatigue)
+
+	fmt.Println(a.GetNiceRoute(route.Systems))
+	for i, leg := range plan.Legs {
+		fmt.Printf("  %s -> %s: %.2f ly, %.0f isotopes, wait %v, fatigue %v, reactivation %v\n", a.universe.SystemName(route.Systems[i]), a.universe.SystemName(route.Systems[i+1]), leg.DistanceLY, leg.Isotopes, leg.Wait.Round(time.Second), leg.FatigueAfter, leg.Reactivation)
+	}
+	fmt.Printf("  %d jumps, %.2f ly total, %.0f isotopes, %v waiting, %v fatigue at the end\n", route.Jumps, route.DistanceLY, plan.Isotopes, plan.Wait.Round(time.Second), plan.FinalFatigue)
 
 	return nil
 }
@@ -117,73 +449,133 @@ func (a *App) Run() error {
 func (a *App) GetNiceRoute(route []int) []string {
 	niceRoute := make([]string, len(route))
 	for j, id := range route {
-		niceRoute[j] = fmt.Sprintf("%s [%s]", a.reverseSystems[id], a.systemSec[id][0:1])
+		sd := a.universe.System(id)
+		niceRoute[j] = fmt.Sprintf("%s [%s]", sd.Name, sd.SecStatus[0:1])
 	}
 
 	return niceRoute
 }
 
-func (a *App) loadSystemData() error {
-	f, err := os.Open(a.systemDataFile)
-	if err != nil {
-		return errors.Wrap(err, "could not open system data")
+func (a *App) GetRouteStats(route path.Route) string {
+	secNames := make([]string, 0, len(route.SecCounts))
+	for sec := range route.SecCounts {
+		secNames = append(secNames, sec)
+	}
+	sort.Strings(secNames)
+
+	secCounts := make([]string, len(secNames))
+	for i, sec := range secNames {
+		secCounts[i] = fmt.Sprintf("%s=%d", sec, route.SecCounts[sec])
+	}
+
+	stats := fmt.Sprintf("  from %s, %d jumps, sec: %s", a.universe.SystemName(route.Source), route.Jumps, strings.Join(secCounts, " "))
+	if a.pathfinder.HasGatePositions() { // otherwise the ETA is only a guess
+		stats += fmt.Sprintf(", eta %v", route.ETA.Round(time.Second))
+	}
+	if violated := append(a.universe.TagNames(route.Violated), universe.ExprNames(route.ViolatedExprs)...); len(violated) > 0 {
+		stats += fmt.Sprintf(", passes through %v", violated)
+	}
+	for _, e := range route.Edges {
+		if e.Kind != path.Stargate {
+			stats += fmt.Sprintf(", %s %s -> %s (%s)", e.Kind, a.universe.SystemName(e.From), a.universe.SystemName(e.To), e.Label)
+		}
+	}
+	if relaxed := append(a.universe.TagNames(route.Relaxed), universe.ExprNames(route.RelaxedExprs)...); len(relaxed) > 0 {
+		stats += fmt.Sprintf(", relaxed %v", relaxed)
+	}
+	if len(route.PriorityCounts) > 0 {
+		stats += fmt.Sprintf(", priorities %v", route.PriorityCounts)
+	}
+
+	return stats
+}
+
+// parsePriorities parses priorities given as tag expressions, each optionally
+// suffixed with :min (the default) or :max.
+func (a *App) parsePriorities(priorities []string) ([]path.Priority, error) {
+	parsed := make([]path.Priority, len(priorities))
+	for i, p := range priorities {
+		switch {
+		case strings.HasSuffix(p, ":max"):
+			parsed[i].Maximize = true
+			p = strings.TrimSuffix(p, ":max")
+		case strings.HasSuffix(p, ":min"):
+			p = strings.TrimSuffix(p, ":min")
+		}
+
+		var err error
+		if parsed[i].Expr, err = tagexpr.Parse(p, a.universe.TagID); err != nil {
+			return nil, err
+		}
 	}
-	defer deferutil.CheckDefer(f.Close)
 
-	dataContents := DataContents{}
+	return parsed, nil
+}
+
+// runPareto lists the routes trading total jumps off against the --pareto
+// measures, fewest jumps first.
+func (a *App) runPareto(q path.Query) error {
+	measures, err := a.parseMeasures(a.pareto)
+	if err != nil {
+		return errors.Wrap(err, "bad pareto measures")
+	}
 
-	decoder := yaml.NewDecoder(f)
-	if err := decoder.Decode(&dataContents); err != nil {
-		return errors.Wrap(err, "could not yaml decode system data")
+	routes, err := a.pathfinder.ParetoRoutes(context.Background(), q, measures)
+	if err != nil {
+		return errors.Wrap(err, "could not find a viable route")
 	}
 
-	a.rawDataContents = dataContents
+	for _, pr := range routes {
+		fmt.Println(pr.Tradeoff(measures))
+		fmt.Println(a.GetNiceRoute(pr.Route.Systems))
+		fmt.Println(a.GetRouteStats(pr.Route))
+	}
 
 	return nil
 }
 
-func (a *App) preparePathFinder() error {
-	numSystems := len(a.rawDataContents.SystemData)
-	graph := make([][]int, numSystems)
-	graphTags := make([][]bool, numSystems)
-
-	// first pass
-	for _, sd := range a.rawDataContents.SystemData {
-		// populate system name lookups
-		a.systems[sd.Name] = sd.ID
-		a.reverseSystems[sd.ID] = sd.Name
-		a.systemSec[sd.ID] = sd.SecStatus
-
-		// populate tag name lookups
-		for _, t := range sd.Tags {
-			var tid int
-			var ok bool
-			if _, ok = a.tags[t]; !ok {
-				tid = len(a.tags)
-				a.tags[t] = tid
-				a.reverseTags[tid] = t
+// parseMeasures parses Pareto measures given as tag expressions, or "danger"
+// for the danger scores from the kill file.
+func (a *App) parseMeasures(names []string) ([]path.Measure, error) {
+	measures := make([]path.Measure, len(names))
+	for i, name := range names {
+		measures[i].Name = name
+
+		if name == "danger" {
+			if a.killFile == "" {
+				return nil, errors.New("must provide a kill file to trade off danger")
 			}
-		}
-	}
 
-	// second pass
-	for _, sd := range a.rawDataContents.SystemData {
-		graph[sd.ID] = make([]int, len(sd.Destinations))
-		graphTags[sd.ID] = make([]bool, len(a.tags))
+			kills, err := danger.Load(a.killFile)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not load kill activity")
+			}
 
-		// set destinations
-		for i, d := range sd.Destinations {
-			graph[sd.ID][i] = a.systems[d]
+			measures[i].Scores = map[int]float64{}
+			for sname, score := range a.dangerModel.Score(kills, time.Now()).Danger {
+				if sys, ok := a.universe.SystemID(sname); ok {
+					measures[i].Scores[sys] = score
+				}
+			}
+			continue
 		}
 
-		// set tags
-		for _, t := range sd.Tags {
-			tid := a.tags[t]
-			graphTags[sd.ID][tid] = true
+		var err error
+		if measures[i].Expr, err = tagexpr.Parse(name, a.universe.TagID); err != nil {
+			return nil, err
 		}
 	}
 
-	a.pathfinder = path.NewFinder(graph, graphTags)
+	return measures, nil
+}
 
-	return nil
+func (a *App) edgeName(e path.Edge) string {
+	switch {
+	case e.Kind == path.Stargate && e.Gate != 0:
+		return fmt.Sprintf("%s [%d]", e.Label, e.Gate)
+	case e.Label != "":
+		return fmt.Sprintf("%s (%s)", e.Kind, e.Label)
+	default:
+		return e.Kind.String()
+	}
 }
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/path"
+	"github.com/pkg/errors"
+)
+
+// RunCentrality ranks systems by how many shortest routes pass through them.
+func (a *App) RunCentrality() error {
+	if err := a.prepare(); err != nil {
+		return err
+	}
+
+	q := path.CentralityQuery{
+		Weights: map[int]float64{},
+		Limit:   a.limit,
+	}
+
+	tagIDs, err := a.universe.TagIDs(a.withinTags)
+	if err != nil {
+		return err
+	}
+	q.WithinTags = tagIDs
+
+	if q.WithinExprs, err = a.universe.ParseExprs(a.withinExprs); err != nil {
+		return errors.Wrap(err, "bad within expression")
+	}
+
+	for name, w := range a.weights {
+		ids, err := a.universe.SystemIDs([]string{name})
+		if err != nil {
+			return err
+		}
+		q.Weights[ids[0]] = float64(w)
+	}
+
+	if q.Constraints, err = a.constraints(); err != nil {
+		return err
+	}
+
+	fmt.Printf("centrality within: %v %v, weights: %v, avoid: %v, avoid tags: %v\n", a.withinTags, a.withinExprs, a.weights, a.avoidSystems, a.avoidTags)
+
+	ranked, err := a.pathfinder.RankCentrality(context.Background(), q)
+	if err != nil {
+		return errors.Wrap(err, "could not rank systems")
+	}
+
+	for i, c := range ranked {
+		fmt.Printf("%d. %s [%s]: %.0f (%.3f)\n", i+1, a.universe.SystemName(c.System), a.universe.System(c.System).SecStatus, c.Score, c.Relative)
+	}
+
+	return nil
+}
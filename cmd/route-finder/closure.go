@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/path"
+	"github.com/pkg/errors"
+)
+
+// defaultHubs are the trade hubs checked when no hubs are given.
+var defaultHubs = []string{"Jita", "Amarr", "Dodixie", "Rens", "Hek"}
+
+// RunClosure reports what closing the systems in --close and the gates in
+// --close-gates would do to the routes between the hubs.
+func (a *App) RunClosure() error {
+	if len(a.closeSystems) == 0 && len(a.closeGates) == 0 {
+		return errors.New("must provide systems or gates to close")
+	}
+
+	if err := a.prepare(); err != nil {
+		return err
+	}
+
+	hubs := a.hubs
+	if len(hubs) == 0 && len(a.hubTags) == 0 && len(a.hubExprs) == 0 {
+		hubs = defaultHubs
+	}
+
+	q := path.ClosureQuery{
+		SampleSize: a.sampleSize,
+		Seed:       a.seed,
+		Limit:      a.limit,
+	}
+
+	var err error
+	if q.Closed, err = a.universe.SystemIDs(a.closeSystems); err != nil {
+		return err
+	}
+
+	if q.ClosedGates, err = a.universe.GateIDs(a.closeGates); err != nil {
+		return errors.Wrap(err, "bad closed gates")
+	}
+
+	if q.Hubs, err = a.universe.SystemIDs(hubs); err != nil {
+		return err
+	}
+
+	tagIDs, err := a.universe.TagIDs(a.hubTags)
+	if err != nil {
+		return err
+	}
+	q.HubTags = tagIDs
+
+	if q.HubExprs, err = a.universe.ParseExprs(a.hubExprs); err != nil {
+		return errors.Wrap(err, "bad hub expression")
+	}
+
+	if q.Constraints, err = a.constraints(); err != nil {
+		return err
+	}
+
+	fmt.Printf("close: %v, close gates: %v, hubs: %v %v %v\n", a.closeSystems, a.closeGates, hubs, a.hubTags, a.hubExprs)
+
+	impact, err := a.pathfinder.AnalyzeClosure(context.Background(), q)
+	if err != nil {
+		return errors.Wrap(err, "could not analyze the closure")
+	}
+
+	fmt.Printf("%d hubs, %d connected pairs: %d disconnected, %d longer, %d unchanged\n", len(impact.Hubs), impact.Pairs, len(impact.Disconnected), len(impact.Longer), impact.Unchanged)
+	for _, p := range impact.Disconnected {
+		fmt.Printf("disconnected: %s - %s\n", a.universe.SystemName(p.From), a.universe.SystemName(p.To))
+	}
+	for _, d := range impact.Longer {
+		fmt.Printf("longer: %s - %s, %d -> %d jumps (+%d)\n", a.universe.SystemName(d.From), a.universe.SystemName(d.To), d.Before, d.After, d.After-d.Before)
+		fmt.Printf("  %v\n", a.GetNiceRoute(d.Route.Systems))
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/courier"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/path"
+	"github.com/pkg/errors"
+)
+
+// RunCourier plans a route from the first --from-systems system through the
+// pickups and dropoffs of the contracts in --contracts.
+func (a *App) RunCourier() error {
+	if len(a.fromSystems) != 1 {
+		return errors.New("must provide exactly one start system")
+	}
+
+	if a.contractFile == "" {
+		return errors.New("must provide a contract file")
+	}
+
+	contracts, err := courier.Load(a.contractFile)
+	if err != nil {
+		return errors.Wrap(err, "could not load contracts")
+	}
+
+	if err := a.prepare(); err != nil {
+		return err
+	}
+
+	start, err := a.universe.SystemIDs(a.fromSystems)
+	if err != nil {
+		return err
+	}
+
+	q := path.CourierQuery{
+		Start:       start[0],
+		Deliveries:  make([]path.Delivery, len(contracts)),
+		HighSecOnly: a.highSecOnly,
+	}
+
+	for i, c := range contracts {
+		ids, err := a.universe.SystemIDs([]string{c.Pickup, c.Dropoff})
+		if err != nil {
+			return errors.Wrapf(err, "bad contract %s", c.ID)
+		}
+		q.Deliveries[i] = path.Delivery{Pickup: ids[0], Dropoff: ids[1]}
+	}
+
+	if q.Constraints, err = a.constraints(); err != nil {
+		return err
+	}
+
+	fmt.Printf("courier from: %s, contracts: %d, high-sec only: %v, avoid: %v, avoid tags: %v\n", a.fromSystems[0], len(contracts), a.highSecOnly, a.avoidSystems, a.avoidTags)
+
+	plan, err := a.pathfinder.PlanCourier(context.Background(), q)
+	if err != nil {
+		return errors.Wrap(err, "could not plan a courier route")
+	}
+
+	for _, stop := range plan.Stops {
+		fmt.Printf("%s: pick up %v, drop off %v\n", a.universe.SystemName(stop.System), contractIDs(contracts, stop.Pickups), contractIDs(contracts, stop.Dropoffs))
+	}
+	fmt.Println(a.GetRouteStats(plan.Route))
+	fmt.Printf("  %v\n", a.GetNiceRoute(plan.Route.Systems))
+
+	return nil
+}
+
+func contractIDs(contracts []courier.Contract, indices []int) []string {
+	ids := make([]string, len(indices))
+	for i, idx := range indices {
+		ids[i] = contracts[idx].ID
+	}
+
+	return ids
+}
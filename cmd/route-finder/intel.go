@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/chatlog"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/path"
+	"github.com/pkg/errors"
+)
+
+// intelWatch tracks where the pilot is and the route they are flying, so intel
+// can be reported relative to them.
+type intelWatch struct {
+	a     *App
+	index *chatlog.Index
+
+	located bool
+	here    int
+	route   []int
+}
+
+// RunIntel tails the EVE chat logs and prints a line for each intel report,
+// warning about hostiles on the pilot's route or within --warn-jumps of them.
+// The pilot's location starts at the first --from-systems entry and follows
+// the Local channel when its logs are in the directory.
+func (a *App) RunIntel() error {
+	if a.logDir == "" {
+		return errors.New("must provide a chat log directory")
+	}
+
+	if a.pollInterval <= 0 {
+		return errors.New("--poll must be positive")
+	}
+
+	if err := a.prepare(); err != nil {
+		return err
+	}
+
+	names := make(map[string]int, len(a.universe.Systems()))
+	for _, sd := range a.universe.Systems() {
+		names[sd.Name] = sd.ID
+	}
+
+	iw := &intelWatch{
+		a:     a,
+		index: chatlog.NewIndex(names),
+	}
+
+	if len(a.fromSystems) > 0 {
+		here, err := a.universe.SystemIDs(a.fromSystems[:1])
+		if err != nil {
+			return err
+		}
+		iw.moveTo(here[0])
+	}
+
+	var channels []string
+	if len(a.channels) > 0 {
+		channels = append(append(channels, a.channels...), "Local")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	go func() {
+		<-interrupts
+		cancel()
+	}()
+
+	fmt.Printf("watching %s for intel, channels: %v\n", a.logDir, a.channels)
+
+	tailer := chatlog.NewTailer(a.logDir, channels, a.fromStart)
+
+	return tailer.Tail(ctx, a.pollInterval, func(channel string, m chatlog.Message) {
+		if sys, ok := iw.index.Moved(m); ok {
+			iw.moveTo(sys)
+			return
+		}
+
+		if strings.EqualFold(channel, "Local") {
+			return
+		}
+
+		if e, ok := iw.index.Intel(channel, m); ok {
+			iw.report(e)
+		}
+	})
+}
+
+// moveTo updates the pilot's location, finding a new route to the target if
+// they have left the current one.
+func (iw *intelWatch) moveTo(sys int) {
+	iw.here = sys
+	iw.located = true
+
+	if at := iw.routeIndex(sys); at >= 0 {
+		fmt.Printf("now in %s, %d jumps to go\n", iw.a.universe.SystemName(sys), len(iw.route)-1-at)
+		return
+	}
+
+	iw.route = nil
+
+	a := iw.a
+	if a.toSystem == "" && a.toTag == "" && a.toExpr == "" {
+		return
+	}
+
+	q, err := a.query()
+	if err != nil {
+		fmt.Printf("could not plan route: %v\n", err)
+		return
+	}
+	q.Sources = []int{sys}
+	q.Limit = 1
+
+	routes, err := a.pathfinder.Route(context.Background(), q)
+	if err != nil {
+		fmt.Printf("could not plan route from %s: %v\n", a.universe.SystemName(sys), err)
+		return
+	}
+
+	iw.route = routes[0].Systems
+	fmt.Printf("now in %s, route: %v\n", a.universe.SystemName(sys), a.GetNiceRoute(iw.route))
+}
+
+func (iw *intelWatch) routeIndex(sys int) int {
+	for i, s := range iw.route {
+		if s == sys {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (iw *intelWatch) report(e chatlog.Event) {
+	a := iw.a
+
+	fmt.Printf("[%s] %s > %s: %s\n", e.Time.Format("15:04:05"), e.Channel, e.Sender, e.Text)
+
+	if e.Clear {
+		return
+	}
+
+	for _, sys := range e.Systems {
+		if a.reportTo != "" {
+			if err := a.postIntel(sys, e); err != nil {
+				fmt.Printf("  could not report intel: %v\n", err)
+			}
+		}
+
+		if !iw.located {
+			continue
+		}
+
+		name := a.universe.SystemName(sys)
+		if at, here := iw.routeIndex(sys), iw.routeIndex(iw.here); at >= 0 && here >= 0 && at >= here {
+			fmt.Printf("  WARNING: hostile in %s, %d jumps from you on your route\n", name, at-here)
+			continue
+		}
+
+		jumps, ok := a.jumpsBetween(iw.here, sys)
+		if ok && jumps <= a.warnJumps {
+			fmt.Printf("  hostile in %s, %d jumps from you\n", name, jumps)
+		}
+	}
+}
+
+// jumpsBetween is the shortest stargate distance from one system to another.
+func (a *App) jumpsBetween(from, to int) (int, bool) {
+	if from == to {
+		return 0, true
+	}
+
+	routes, err := a.pathfinder.Route(context.Background(), path.Query{
+		Sources:  []int{from},
+		Targets:  []int{to},
+		MaxJumps: a.warnJumps,
+		Limit:    1,
+	})
+	if err != nil {
+		return 0, false
+	}
+
+	return routes[0].Jumps, true
+}
+
+// postIntel reports a hostile sighting to a route-server's shared intel store.
+func (a *App) postIntel(sys int, e chatlog.Event) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"system":      a.universe.SystemName(sys),
+		"ttl_minutes": a.reportTTL,
+		"reporter":    e.Sender,
+		"note":        e.Text,
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not encode intel report")
+	}
+
+	resp, err := http.Post(strings.TrimRight(a.reportTo, "/")+"/report_intel", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "could not post intel report")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("route-server returned %s", resp.Status)
+	}
+
+	return nil
+}
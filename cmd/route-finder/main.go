@@ -3,23 +3,128 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/gsmcwhirter/eve-route-finder/pkg/path"
 	"github.com/spf13/pflag"
 )
 
 func run() error {
 	app := NewApp()
 
+	// an optional subcommand comes before the flags
+	command, args := "", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
 	pflag.StringVarP(&app.systemDataFile, "system-data", "s", "", "system data file (generated by graphmaker)")
 	pflag.StringSliceVarP(&app.fromSystems, "from-systems", "f", nil, "systems to start from")
 	pflag.StringVarP(&app.toSystem, "to-system", "t", "", "system to go to")
 	pflag.StringVarP(&app.toTag, "to-tag", "g", "", "tag to go to")
+	pflag.StringVarP(&app.toExpr, "to-expr", "e", "", "tag expression to go to, e.g. 'low & !Placid'")
 	pflag.StringSliceVarP(&app.avoidSystems, "avoid-systems", "i", nil, "systems to avoid")
+	pflag.StringSliceVar(&app.avoidGates, "avoid-gates", nil, "stargates to avoid, as system:destination or gate IDs")
+	pflag.BoolVar(&app.showGates, "show-gates", false, "list the gate or bridge taken on each jump")
 	pflag.StringSliceVarP(&app.avoidTags, "avoid-tags", "j", nil, "tags to avoid")
+	pflag.StringArrayVar(&app.avoidExprs, "avoid-expr", nil, "tag expression to avoid (repeatable)")
 	pflag.StringSliceVarP(&app.preferNotTags, "prefer-not", "p", nil, "tags to try and avoid")
-	pflag.Parse()
+	pflag.StringArrayVar(&app.preferNotExprs, "prefer-not-expr", nil, "tag expression to try and avoid (repeatable)")
+	pflag.IntVarP(&app.maxJumps, "max-jumps", "m", 0, "maximum total jumps (0 for no limit)")
+	pflag.StringToIntVar(&app.maxTagJumps, "max-tag-jumps", nil, "maximum jumps into systems with each tag, e.g. low=3,null=0")
+	pflag.StringArrayVar(&app.priorities, "priority", nil, "tag expression to minimize jumps into, or maximize with a :max suffix, before total jumps (repeatable, in order)")
+	pflag.StringArrayVar(&app.pareto, "pareto", nil, "tag expression, or danger with --kills, to trade off against total jumps; lists every route no other beats on all of them (repeatable)")
+	pflag.StringVar(&app.killFile, "kills", "", "kill activity file (csv or json) for danger scores")
+	pflag.DurationVar(&app.dangerModel.Window, "danger-window", 24*time.Hour, "ignore kills older than this")
+	pflag.DurationVar(&app.dangerModel.HalfLife, "danger-half-life", 2*time.Hour, "time for a kill's danger to halve")
+	pflag.StringVarP(&app.bridgeFile, "bridges", "b", "", "jump bridge file")
+	pflag.BoolVar(&app.useBridges, "use-bridges", false, "route through jump bridges")
+	pflag.StringSliceVar(&app.bridgeOwners, "bridge-owners", nil, "only use jump bridges with these owners")
+	pflag.IntVar(&app.bridgeCost, "bridge-cost", 1, "cost of a jump bridge jump, in stargate jumps")
+	pflag.BoolVar(&app.jumpDrive, "jump-drive", false, "find a jump-drive route instead of a stargate route")
+	pflag.StringVar(&app.shipClass, "ship-class", "capital", "ship class for jump-drive range")
+	pflag.Float64Var(&app.jumpRangeLY, "jump-range-ly", 0, "jump-drive range in light years (overrides ship class)")
+	pflag.StringToStringVar(&app.jumpRangeFlags, "jump-range", nil, "jump range overrides in light years per ship class, e.g. capital=7,black-ops=8")
+	pflag.StringVar(&app.objective, "objective", "jumps", "stargate route objective: jumps or time")
+	pflag.StringVar(&app.jumpObjective, "jump-objective", "jumps", "jump-drive route objective: jumps, distance, or fatigue")
+	pflag.DurationVar(&app.travel.AlignTime, "align-time", path.DefaultTravel.AlignTime, "ship align time")
+	pflag.Float64Var(&app.travel.WarpSpeed, "warp-speed", path.DefaultTravel.WarpSpeed, "ship warp speed in AU/s")
+	pflag.DurationVar(&app.travel.JumpTime, "jump-time", path.DefaultTravel.JumpTime, "time to jump through a gate, including session change")
+	pflag.DurationVar(&app.travel.GateCloak, "gate-cloak", path.DefaultTravel.GateCloak, "time spent in gate cloak after each jump")
+	pflag.Float64Var(&app.travel.FallbackWarpAU, "fallback-warp-au", path.DefaultTravel.FallbackWarpAU, "warp length to assume where gate positions are unknown")
+	pflag.IntVar(&app.jumpSkills.FuelConservation, "fuel-conservation", 0, "Jump Fuel Conservation skill level")
+	pflag.IntVar(&app.jumpSkills.HullSkill, "hull-skill", 0, "hull fuel skill level (e.g. Jump Freighters)")
+	pflag.DurationVar(&app.jumpFatigue, "fatigue", 0, "current jump fatigue, e.g. 45m")
+	pflag.StringVar(&app.profileFile, "profiles", "", "ship profile file")
+	pflag.StringVar(&app.profileName, "profile", "", "ship profile to route for")
+	pflag.IntVar(&app.limit, "limit", 0, "maximum number of results (0 for the default); with a target tag or expression, the number of nearest systems to route to")
+	pflag.StringVar(&app.meetBy, "meet-by", "max", "rendezvous: minimize the max or total jumps")
+	pflag.StringToIntVar(&app.weights, "weights", nil, "rendezvous and centrality: weights by system, e.g. Jita=3")
+	pflag.StringSliceVar(&app.targets, "targets", nil, "staging: target systems")
+	pflag.IntVar(&app.stagingRange, "range", 5, "staging: jumps a target may be from the staging system")
+	pflag.StringSliceVar(&app.candidateTags, "candidate-tags", nil, "staging: only consider systems with these tags")
+	pflag.StringArrayVar(&app.candidateExprs, "candidate-expr", nil, "staging: only consider systems matching this tag expression (repeatable)")
+	pflag.IntVar(&app.roamSystems, "roam-systems", 8, "roam: systems to visit before returning home")
+	pflag.StringSliceVar(&app.preferTags, "prefer-tags", nil, "roam: tags to roam through")
+	pflag.StringArrayVar(&app.preferExprs, "prefer-expr", nil, "roam: tag expression to roam through (repeatable)")
+	pflag.Int64Var(&app.seed, "seed", 0, "roam and closure: random seed (0 for a different roam each run)")
+	pflag.BoolVar(&app.returnHome, "return", false, "sweep: end the tour back at the start")
+	pflag.StringVar(&app.contractFile, "contracts", "", "courier: contract file (.csv or .json)")
+	pflag.BoolVar(&app.highSecOnly, "high-sec-only", false, "courier: stay in high-sec")
+	pflag.StringSliceVar(&app.closeSystems, "close", nil, "closure: systems to make impassable")
+	pflag.StringSliceVar(&app.closeGates, "close-gates", nil, "closure: stargates to close, as system:destination or gate IDs")
+	pflag.StringSliceVar(&app.hubs, "hubs", nil, "closure: hubs to check routes between (default the trade hubs)")
+	pflag.StringSliceVar(&app.hubTags, "hub-tags", nil, "closure: check routes between systems with these tags")
+	pflag.StringArrayVar(&app.hubExprs, "hub-expr", nil, "closure: check routes between systems matching this tag expression (repeatable)")
+	pflag.IntVar(&app.sampleSize, "sample", 0, "closure: only check this many hubs, picked at random (0 for all)")
+	pflag.StringSliceVar(&app.withinTags, "within-tags", nil, "centrality: only count routes through systems with these tags")
+	pflag.StringArrayVar(&app.withinExprs, "within-expr", nil, "centrality: only count routes through systems matching this tag expression (repeatable)")
+	pflag.StringVar(&app.logDir, "log-dir", "", "intel: EVE chat log directory")
+	pflag.StringSliceVar(&app.channels, "channel", nil, "intel: chat channels to watch")
+	pflag.BoolVar(&app.fromStart, "from-start", false, "intel: read existing logs from the beginning")
+	pflag.IntVar(&app.warnJumps, "warn-jumps", 5, "intel: warn about hostiles within this many jumps")
+	pflag.DurationVar(&app.pollInterval, "poll", 2*time.Second, "intel: how often to check the logs")
+	pflag.StringVar(&app.reportTo, "report-to", "", "intel: route-server URL to post intel reports to")
+	pflag.Float64Var(&app.reportTTL, "report-ttl", 15, "intel: minutes posted intel reports last")
+	if err := pflag.CommandLine.Parse(args); err != nil {
+		return err
+	}
+
+	if err := app.applyProfile(pflag.CommandLine.Changed); err != nil {
+		return err
+	}
 
-	return app.Run()
+	if app.jumpDrive && pflag.CommandLine.Changed("objective") {
+		return fmt.Errorf("--objective is for stargate routes; use --jump-objective with --jump-drive")
+	}
+
+	if !app.jumpDrive && pflag.CommandLine.Changed("jump-objective") {
+		return fmt.Errorf("--jump-objective needs --jump-drive")
+	}
+
+	switch command {
+	case "", "route":
+		return app.Run()
+	case "intel":
+		return app.RunIntel()
+	case "rendezvous":
+		return app.RunRendezvous()
+	case "staging":
+		return app.RunStaging()
+	case "roam":
+		return app.RunRoam()
+	case "sweep":
+		return app.RunSweep()
+	case "courier":
+		return app.RunCourier()
+	case "closure":
+		return app.RunClosure()
+	case "centrality":
+		return app.RunCentrality()
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
 }
 
 func main() {
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/path"
+	"github.com/pkg/errors"
+)
+
+// RunRendezvous finds the best systems for the fleet members in --from-systems
+// to meet in.
+func (a *App) RunRendezvous() error {
+	if len(a.fromSystems) == 0 {
+		return errors.New("must provide the fleet members' systems")
+	}
+
+	if err := a.prepare(); err != nil {
+		return err
+	}
+
+	objective, err := path.ParseRendezvousObjective(a.meetBy)
+	if err != nil {
+		return err
+	}
+
+	ids, err := a.universe.SystemIDs(a.fromSystems)
+	if err != nil {
+		return err
+	}
+
+	members := make([]path.Member, len(ids))
+	for i, id := range ids {
+		members[i] = path.Member{System: id, Weight: float64(a.weights[a.fromSystems[i]])}
+	}
+
+	constraints, err := a.constraints()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("rendezvous for: %v, by: %s, avoid: %v, avoid tags: %v\n", a.fromSystems, a.meetBy, a.avoidSystems, a.avoidTags)
+
+	found, err := a.pathfinder.FindRendezvous(context.Background(), path.RendezvousQuery{
+		Members:     members,
+		Constraints: constraints,
+		Objective:   objective,
+		Limit:       a.limit,
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not find a rendezvous")
+	}
+
+	for _, r := range found {
+		fmt.Printf("%s: max %d jumps, total %d jumps\n", a.universe.SystemName(r.System), r.MaxJumps, r.TotalJumps)
+		for _, route := range r.Routes {
+			fmt.Printf("  %s (%d jumps): %v\n", a.universe.SystemName(route.Source), route.Jumps, a.GetNiceRoute(route.Systems))
+		}
+	}
+
+	return nil
+}
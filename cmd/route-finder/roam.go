@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/path"
+	"github.com/pkg/errors"
+)
+
+// RunRoam finds loops out of the first --from-systems system and back through
+// --roam-systems other systems.
+func (a *App) RunRoam() error {
+	if len(a.fromSystems) != 1 {
+		return errors.New("must provide exactly one home system")
+	}
+
+	if err := a.prepare(); err != nil {
+		return err
+	}
+
+	home, err := a.universe.SystemIDs(a.fromSystems)
+	if err != nil {
+		return err
+	}
+
+	q := path.RoamQuery{
+		Home:     home[0],
+		Systems:  a.roamSystems,
+		MaxJumps: a.maxJumps,
+		Limit:    a.limit,
+		Seed:     a.seed,
+	}
+
+	tagIDs, err := a.universe.TagIDs(a.preferTags)
+	if err != nil {
+		return err
+	}
+	q.PreferTags = tagIDs
+
+	if q.PreferExprs, err = a.universe.ParseExprs(a.preferExprs); err != nil {
+		return errors.Wrap(err, "bad prefer expression")
+	}
+
+	if q.Constraints, err = a.constraints(); err != nil {
+		return err
+	}
+
+	fmt.Printf("roam from: %s, systems: %d, max jumps: %d, prefer: %v %v, avoid: %v, avoid tags: %v\n", a.fromSystems[0], a.roamSystems, a.maxJumps, a.preferTags, a.preferExprs, a.avoidSystems, a.avoidTags)
+
+	roams, err := a.pathfinder.FindRoams(context.Background(), q)
+	if err != nil {
+		return errors.Wrap(err, "could not find a roam")
+	}
+
+	for _, r := range roams {
+		fmt.Printf("%d jumps, %d preferred systems (%s)\n", r.Route.Jumps, r.Preferred, a.GetRouteStats(r.Route))
+		fmt.Printf("  %v\n", a.GetNiceRoute(r.Route.Systems))
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/path"
+	"github.com/pkg/errors"
+)
+
+// RunStaging ranks systems by how many of the targets (--targets, --to-tag,
+// or --to-expr) lie within --range jumps of them.
+func (a *App) RunStaging() error {
+	if len(a.targets) == 0 && a.toTag == "" && a.toExpr == "" {
+		return errors.New("must provide target systems, a tag, or an expression")
+	}
+
+	if err := a.prepare(); err != nil {
+		return err
+	}
+
+	q := path.StagingQuery{
+		Range: a.stagingRange,
+		Limit: a.limit,
+	}
+
+	var err error
+	if q.Targets, err = a.universe.SystemIDs(a.targets); err != nil {
+		return err
+	}
+
+	if a.toTag != "" {
+		tagIDs, err := a.universe.TagIDs([]string{a.toTag})
+		if err != nil {
+			return err
+		}
+		q.TargetTags = tagIDs
+	}
+
+	if a.toExpr != "" {
+		if q.TargetExprs, err = a.universe.ParseExprs([]string{a.toExpr}); err != nil {
+			return errors.Wrap(err, "bad target expression")
+		}
+	}
+
+	tagIDs, err := a.universe.TagIDs(a.candidateTags)
+	if err != nil {
+		return err
+	}
+	q.CandidateTags = tagIDs
+
+	if q.CandidateExprs, err = a.universe.ParseExprs(a.candidateExprs); err != nil {
+		return errors.Wrap(err, "bad candidate expression")
+	}
+
+	if q.Constraints, err = a.constraints(); err != nil {
+		return err
+	}
+
+	fmt.Printf("staging for: %v %s %s, range: %d, candidates: %v %v\n", a.targets, a.toTag, a.toExpr, a.stagingRange, a.candidateTags, a.candidateExprs)
+
+	found, err := a.pathfinder.RankStaging(context.Background(), q)
+	if err != nil {
+		return errors.Wrap(err, "could not rank staging systems")
+	}
+
+	for _, s := range found {
+		fmt.Printf("%s: covers %d/%d, average %.1f jumps\n", a.universe.SystemName(s.System), len(s.Covered), s.Targets, s.AverageJumps)
+		for _, r := range s.Covered {
+			fmt.Printf("  %s (%d jumps)\n", a.universe.SystemName(r.System), r.Jumps)
+		}
+	}
+
+	return nil
+}
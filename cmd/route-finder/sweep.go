@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/path"
+	"github.com/pkg/errors"
+)
+
+// RunSweep plans a tour from the first --from-systems system through every
+// system in --targets, --to-tag, or --to-expr.
+func (a *App) RunSweep() error {
+	if len(a.fromSystems) != 1 {
+		return errors.New("must provide exactly one start system")
+	}
+
+	if len(a.targets) == 0 && a.toTag == "" && a.toExpr == "" {
+		return errors.New("must provide target systems, a tag, or an expression")
+	}
+
+	if err := a.prepare(); err != nil {
+		return err
+	}
+
+	start, err := a.universe.SystemIDs(a.fromSystems)
+	if err != nil {
+		return err
+	}
+
+	q := path.SweepQuery{
+		Start:  start[0],
+		Return: a.returnHome,
+	}
+
+	if q.Targets, err = a.universe.SystemIDs(a.targets); err != nil {
+		return err
+	}
+
+	if a.toTag != "" {
+		tagIDs, err := a.universe.TagIDs([]string{a.toTag})
+		if err != nil {
+			return err
+		}
+		q.TargetTags = tagIDs
+	}
+
+	if a.toExpr != "" {
+		if q.TargetExprs, err = a.universe.ParseExprs([]string{a.toExpr}); err != nil {
+			return errors.Wrap(err, "bad target expression")
+		}
+	}
+
+	if q.Constraints, err = a.constraints(); err != nil {
+		return err
+	}
+
+	fmt.Printf("sweep from: %s, through: %v %s %s, avoid: %v, avoid tags: %v\n", a.fromSystems[0], a.targets, a.toTag, a.toExpr, a.avoidSystems, a.avoidTags)
+
+	sweep, err := a.pathfinder.PlanSweep(context.Background(), q)
+	if err != nil {
+		return errors.Wrap(err, "could not plan a sweep")
+	}
+
+	fmt.Printf("order: %v\n", a.universe.SystemNames(sweep.Order))
+	if len(sweep.Unreachable) > 0 {
+		fmt.Printf("unreachable: %v\n", a.universe.SystemNames(sweep.Unreachable))
+	}
+	fmt.Println(a.GetRouteStats(sweep.Route))
+	fmt.Printf("  %v\n", a.GetNiceRoute(sweep.Route.Systems))
+
+	return nil
+}
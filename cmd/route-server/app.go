@@ -1,74 +1,508 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	stderr "errors"
+	"fmt"
 	"net/http"
-	"os"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/gsmcwhirter/eve-route-finder/pkg/courier"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/danger"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/intel"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/jump"
 	"github.com/gsmcwhirter/eve-route-finder/pkg/path"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/profile"
 	"github.com/gsmcwhirter/eve-route-finder/pkg/system"
-	"github.com/gsmcwhirter/go-util/v7/deferutil"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/universe"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/wormhole"
 	"github.com/pkg/errors"
-	"gopkg.in/yaml.v3"
 )
 
 type App struct {
 	systemDataFile string
 	listen         string
+	jumpRangeFlags map[string]string
+	jumpRanges     jump.Ranges
+	bridgeFile     string
+	wormholeFile   string
+	intelFile      string
+	killFile       string
+	killReload     time.Duration
+	dangerModel    danger.Model
+	profileFile    string
+	profiles       profile.Profiles
 
-	rawDataContents DataContents
-
-	tags              map[string]int
-	reverseTags       map[int]string
-	systems           map[string]int
-	reverseSystems    map[int]string
-	reverseSystemData map[int]system.Data
-
-	systemSec map[int]string
-
+	universe   *universe.Universe
 	pathfinder *path.Finder
+	wormholes  *wormhole.Store
+	intel      *intel.Store
+	danger     *danger.Watcher
 }
 
 func NewApp() *App {
-	return &App{
-		tags:              map[string]int{},
-		reverseTags:       map[int]string{},
-		systems:           map[string]int{},
-		reverseSystems:    map[int]string{},
-		reverseSystemData: map[int]system.Data{},
-		systemSec:         map[int]string{},
-	}
-}
-
-type DataContents struct {
-	SystemData []system.Data
+	return &App{}
 }
 
 func (a *App) Prep() error {
-	if err := a.loadSystemData(); err != nil {
+	u, err := universe.Load(a.systemDataFile)
+	if err != nil {
 		return errors.Wrap(err, "could not load system data")
 	}
+	a.universe = u
+	a.pathfinder = u.Finder
+
+	if !u.Finder.HasCoordinates() || !u.Finder.HasGatePositions() {
+		fmt.Printf("WARNING: %s lacks coordinates; jump routes, the time objective, and ETAs are unavailable until it is regenerated with graphmaker\n", a.systemDataFile)
+	}
+
+	if a.bridgeFile != "" {
+		if err := u.LoadBridges(a.bridgeFile); err != nil {
+			return errors.Wrap(err, "could not load jump bridges")
+		}
+	}
+
+	wormholes, err := wormhole.NewStore(a.wormholeFile)
+	if err != nil {
+		return errors.Wrap(err, "could not load wormholes")
+	}
+	a.wormholes = wormholes
+
+	intelStore, err := intel.NewStore(a.intelFile)
+	if err != nil {
+		return errors.Wrap(err, "could not load intel")
+	}
+	a.intel = intelStore
+
+	if a.killFile != "" {
+		if a.killReload <= 0 {
+			return errors.New("--kills-reload must be positive")
+		}
+
+		a.danger, err = danger.NewWatcher(a.killFile, a.dangerModel)
+		if err != nil {
+			return errors.Wrap(err, "could not load kill data")
+		}
+
+		go a.danger.Run(context.Background(), a.killReload, func(err error) {
+			fmt.Printf("ERROR: could not reload kill data: %v\n", err)
+		})
+	}
 
-	if err := a.preparePathFinder(); err != nil {
-		return errors.Wrap(err, "could not populate data")
+	if a.profileFile != "" {
+		a.profiles, err = profile.Load(a.profileFile)
+		if err != nil {
+			return errors.Wrap(err, "could not load ship profiles")
+		}
+	}
+
+	jumpRanges, err := jump.NewRanges(a.jumpRangeFlags)
+	if err != nil {
+		return errors.Wrap(err, "could not set jump ranges")
 	}
+	a.jumpRanges = jumpRanges
 
 	return nil
 }
 
 type RouteRequest struct {
-	FromSystems   []string `json:"from_systems"`
-	ToSystem      string   `json:"to_system"`
-	ToTag         string   `json:"to_tag"`
-	AvoidSystems  []string `json:"avoid_systems"`
-	AvoidTags     []string `json:"avoid_tags"`
-	PreferNotTags []string `json:"prefer_not_tags"`
+	Profile        string         `json:"profile"`
+	FromSystems    []string       `json:"from_systems"`
+	ToSystem       string         `json:"to_system"`
+	ToTag          string         `json:"to_tag"`
+	ToExpr         string         `json:"to_expr"`
+	AvoidSystems   []string       `json:"avoid_systems"`
+	AvoidGates     []string       `json:"avoid_gates"`
+	AvoidTags      []string       `json:"avoid_tags"`
+	AvoidExprs     []string       `json:"avoid_exprs"`
+	PreferNotTags  []string       `json:"prefer_not_tags"`
+	PreferNotExprs []string       `json:"prefer_not_exprs"`
+	MaxJumps       int            `json:"max_jumps"`
+	MaxTagJumps    map[string]int `json:"max_tag_jumps"`
+	UseBridges     bool           `json:"use_bridges"`
+	BridgeOwners   []string       `json:"bridge_owners"`
+	BridgeCost     int            `json:"bridge_cost"`
+
+	// Priorities rank routes by jumps into each priority's tag or expression
+	// in order, and then by total jumps; only routes tied on all of them are
+	// returned.
+	Priorities []PriorityRequest `json:"priorities"`
+
+	// Pareto trades jumps off against jumps into each listed tag or tag
+	// expression, or "danger" for the danger score of the systems entered,
+	// and returns every route that no other beats on all of them at once.
+	Pareto []string `json:"pareto"`
+
+	// Limit caps the number of routes returned. With a target tag or
+	// expression, it asks for a route to each of the nearest Limit systems
+	// instead of only the nearest.
+	Limit int `json:"limit"`
+
+	IgnoreWormholes bool   `json:"ignore_wormholes"`
+	ShipSize        string `json:"ship_size"`
+	ShipMass        int64  `json:"ship_mass"`
+
+	// Intel is "avoid" to avoid systems with live hostile reports, or
+	// "penalize" to make jumps into them cost up to IntelPenalty extra jumps,
+	// less as the reports age.
+	Intel        string `json:"intel"`
+	IntelPenalty int    `json:"intel_penalty"`
+
+	// DangerWeight makes each jump cost extra in proportion to the danger score
+	// of the system it enters; systems scoring over MaxDanger are avoided.
+	DangerWeight float64 `json:"danger_weight"`
+	MaxDanger    float64 `json:"max_danger"`
+
+	// Objective is "jumps" (the default) or "time". The travel parameters
+	// override the defaults used for ETAs; times are in seconds and warp speed
+	// in AU/s.
+	Objective string  `json:"objective"`
+	AlignTime float64 `json:"align_time"`
+	WarpSpeed float64 `json:"warp_speed"`
+	JumpTime  float64 `json:"jump_time"`
+	GateCloak float64 `json:"gate_cloak"`
+}
+
+// PriorityRequest is one lexicographic route criterion, a tag or a tag
+// expression to minimize jumps into (the default) or to maximize them.
+type PriorityRequest struct {
+	Tag      string `json:"tag"`
+	Expr     string `json:"expr"`
+	Minimize *bool  `json:"minimize"`
 }
 
 type RouteResponse struct {
-	Error  string
-	Routes [][]system.Data
+	Error   string
+	Routes  [][]system.Data
+	Stats   []RouteStats
+	Blocked *BlockedStats
+	Intel   []IntelStats
+
+	DangerAvoided []string
+}
+
+type RouteStats struct {
+	Source    string         `json:"source"`
+	Jumps     int            `json:"jumps"`
+	Cost      int            `json:"cost"`
+	ETA       float64        `json:"eta_seconds,omitempty"` // left out without gate positions
+	WarpAU    float64        `json:"warp_au,omitempty"`
+	Legs      []RouteLeg     `json:"legs"`
+	SecCounts map[string]int `json:"sec_counts"`
+	Tags      []string       `json:"tags"`
+	Violated  []string       `json:"violated"`
+	Relaxed   []string       `json:"relaxed"`
+	Intel     []string       `json:"intel"`
+
+	Priorities []int `json:"priorities,omitempty"`
+
+	Tradeoff string             `json:"tradeoff,omitempty"`
+	Measures map[string]float64 `json:"measures,omitempty"`
+
+	Danger        float64            `json:"danger"`
+	DangerSystems map[string]float64 `json:"danger_systems,omitempty"`
+}
+
+type RouteLeg struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Kind  string `json:"kind"`
+	Label string `json:"label,omitempty"`
+	Gate  int64  `json:"gate,omitempty"`
+
+	ExpiresInMinutes float64 `json:"expires_in_minutes,omitempty"`
+	Note             string  `json:"note,omitempty"`
+}
+
+type BlockedStats struct {
+	AvoidSystems []string `json:"avoid_systems"`
+	AvoidTags    []string `json:"avoid_tags"`
+	AvoidExprs   []string `json:"avoid_exprs"`
+	Jumps        int      `json:"jumps"`
+}
+
+type JumpRouteRequest struct {
+	Profile      string   `json:"profile"`
+	FromSystems  []string `json:"from_systems"`
+	ToSystem     string   `json:"to_system"`
+	ShipClass    string   `json:"ship_class"`
+	RangeLY      float64  `json:"range_ly"`
+	AvoidSystems []string `json:"avoid_systems"`
+	AvoidTags    []string `json:"avoid_tags"`
+	Objective    string   `json:"objective"`
+
+	FuelConservation int     `json:"fuel_conservation"`
+	HullSkill        int     `json:"hull_skill"`
+	FatigueMinutes   float64 `json:"fatigue_minutes"`
+}
+
+type JumpRouteResponse struct {
+	Error string
+	Route []system.Data
+	Stats *JumpRouteStats
+}
+
+type JumpRouteStats struct {
+	Source              string         `json:"source"`
+	Jumps               int            `json:"jumps"`
+	LegsLY              []float64      `json:"legs_ly"`
+	DistanceLY          float64        `json:"distance_ly"`
+	Legs                []JumpLegStats `json:"legs"`
+	Isotopes            float64        `json:"isotopes"`
+	WaitMinutes         float64        `json:"wait_minutes"`
+	FinalFatigueMinutes float64        `json:"final_fatigue_minutes"`
+}
+
+type JumpLegStats struct {
+	From                string  `json:"from"`
+	To                  string  `json:"to"`
+	DistanceLY          float64 `json:"distance_ly"`
+	EffectiveLY         float64 `json:"effective_ly"`
+	Isotopes            float64 `json:"isotopes"`
+	WaitMinutes         float64 `json:"wait_minutes"`
+	FatigueMinutes      float64 `json:"fatigue_minutes"`
+	ReactivationMinutes float64 `json:"reactivation_minutes"`
+}
+
+type WormholeRequest struct {
+	From           string    `json:"from"`
+	To             string    `json:"to"`
+	ExpiresMinutes float64   `json:"expires_minutes"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	MaxShipSize    string    `json:"max_ship_size"`
+	MaxShipMass    int64     `json:"max_ship_mass"`
+	Note           string    `json:"note"`
+}
+
+type WormholeRemoveRequest struct {
+	ID string `json:"id"`
+}
+
+type WormholeResponse struct {
+	Error     string
+	Wormholes []WormholeStats
+}
+
+type WormholeStats struct {
+	ID               string    `json:"id"`
+	From             string    `json:"from"`
+	To               string    `json:"to"`
+	Expires          time.Time `json:"expires"`
+	ExpiresInMinutes float64   `json:"expires_in_minutes"`
+	MaxShipSize      string    `json:"max_ship_size,omitempty"`
+	MaxShipMass      int64     `json:"max_ship_mass,omitempty"`
+	Note             string    `json:"note,omitempty"`
+}
+
+type IntelRequest struct {
+	System     string  `json:"system"`
+	TTLMinutes float64 `json:"ttl_minutes"`
+	Count      int     `json:"count"`
+	Reporter   string  `json:"reporter"`
+	Note       string  `json:"note"`
+}
+
+type IntelRemoveRequest struct {
+	ID string `json:"id"`
+}
+
+type IntelResponse struct {
+	Error   string
+	Reports []IntelStats
+}
+
+type IntelStats struct {
+	ID               string    `json:"id"`
+	System           string    `json:"system"`
+	Reported         time.Time `json:"reported"`
+	AgeMinutes       float64   `json:"age_minutes"`
+	ExpiresInMinutes float64   `json:"expires_in_minutes"`
+	Weight           float64   `json:"weight"`
+	Effect           string    `json:"effect,omitempty"`
+	Penalty          int       `json:"penalty,omitempty"`
+	Count            int       `json:"count,omitempty"`
+	Reporter         string    `json:"reporter,omitempty"`
+	Note             string    `json:"note,omitempty"`
+}
+
+// ConstraintRequest holds the avoid lists, bridge, wormhole, intel, and
+// danger options shared by the fleet planning endpoints.
+type ConstraintRequest struct {
+	AvoidSystems []string `json:"avoid_systems"`
+	AvoidGates   []string `json:"avoid_gates"`
+	AvoidTags    []string `json:"avoid_tags"`
+	AvoidExprs   []string `json:"avoid_exprs"`
+	UseBridges   bool     `json:"use_bridges"`
+	BridgeOwners []string `json:"bridge_owners"`
+
+	IgnoreWormholes bool   `json:"ignore_wormholes"`
+	ShipSize        string `json:"ship_size"`
+	ShipMass        int64  `json:"ship_mass"`
+
+	// Fleet plans compare jump counts, so intel and danger can only rule
+	// systems out: Intel may be "avoid" but not "penalize", and there is
+	// MaxDanger but no DangerWeight.
+	Intel     string  `json:"intel"`
+	MaxDanger float64 `json:"max_danger"`
+
+	// The travel parameters override the defaults used for ETAs, as for
+	// RouteRequest.
+	AlignTime float64 `json:"align_time"`
+	WarpSpeed float64 `json:"warp_speed"`
+	JumpTime  float64 `json:"jump_time"`
+	GateCloak float64 `json:"gate_cloak"`
+}
+
+type MemberRequest struct {
+	System string  `json:"system"`
+	Weight float64 `json:"weight"`
+}
+
+type RendezvousRequest struct {
+	Members []MemberRequest `json:"members"`
+	ConstraintRequest
+	Objective string `json:"objective"`
+	Limit     int    `json:"limit"`
+}
+
+type RendezvousResponse struct {
+	Error      string
+	Rendezvous []RendezvousStats
+}
+
+type RendezvousStats struct {
+	System      string          `json:"system"`
+	MaxJumps    int             `json:"max_jumps"`
+	TotalJumps  int             `json:"total_jumps"`
+	WeightedMax float64         `json:"weighted_max"`
+	WeightedSum float64         `json:"weighted_sum"`
+	Routes      [][]system.Data `json:"routes"`
+	Stats       []RouteStats    `json:"stats"`
+}
+
+type StagingRequest struct {
+	Targets        []string `json:"targets"`
+	TargetTags     []string `json:"target_tags"`
+	TargetExprs    []string `json:"target_exprs"`
+	CandidateTags  []string `json:"candidate_tags"`
+	CandidateExprs []string `json:"candidate_exprs"`
+	Range          int      `json:"range"`
+	ConstraintRequest
+	Limit int `json:"limit"`
+}
+
+type StagingResponse struct {
+	Error   string
+	Staging []StagingStats
+}
+
+type StagingStats struct {
+	System       string       `json:"system"`
+	Coverage     int          `json:"coverage"`
+	Targets      int          `json:"targets"`
+	AverageJumps float64      `json:"average_jumps"`
+	Covered      []ReachStats `json:"covered"`
+}
+
+type ReachStats struct {
+	System string `json:"system"`
+	Jumps  int    `json:"jumps"`
+}
+
+type RoamRequest struct {
+	Home        string   `json:"home"`
+	Systems     int      `json:"systems"`
+	MaxJumps    int      `json:"max_jumps"`
+	PreferTags  []string `json:"prefer_tags"`
+	PreferExprs []string `json:"prefer_exprs"`
+	ConstraintRequest
+	Limit int   `json:"limit"`
+	Seed  int64 `json:"seed"`
+}
+
+type RoamResponse struct {
+	Error string
+	Roams []RoamStats
+}
+
+type RoamStats struct {
+	Route     []system.Data `json:"route"`
+	Preferred int           `json:"preferred"`
+	Stats     RouteStats    `json:"stats"`
+}
+
+type SweepRequest struct {
+	Start       string   `json:"start"`
+	Targets     []string `json:"targets"`
+	TargetTags  []string `json:"target_tags"`
+	TargetExprs []string `json:"target_exprs"`
+	Return      bool     `json:"return"`
+	ConstraintRequest
+}
+
+type SweepResponse struct {
+	Error       string
+	Order       []string
+	Route       []system.Data
+	Stats       RouteStats
+	Unreachable []string
+}
+
+// CourierRequest takes contracts either as a list or as CSV text with a header
+// row, as in a contract file. The CSV text wins if both are given.
+type CourierRequest struct {
+	Start        string             `json:"start"`
+	Contracts    []courier.Contract `json:"contracts"`
+	ContractsCSV string             `json:"contracts_csv"`
+	HighSecOnly  bool               `json:"high_sec_only"`
+	ConstraintRequest
+}
+
+type CourierResponse struct {
+	Error string
+	Stops []CourierStop
+	Route []system.Data
+	Stats RouteStats
+}
+
+type CourierStop struct {
+	System   string   `json:"system"`
+	Pickups  []string `json:"pickups"`
+	Dropoffs []string `json:"dropoffs"`
+}
+
+// ClosureRequest asks what closing systems or gates would do to the routes
+// between hubs, by default the trade hubs.
+type ClosureRequest struct {
+	ClosedSystems []string `json:"closed_systems"`
+	ClosedGates   []string `json:"closed_gates"`
+	Hubs          []string `json:"hubs"`
+	HubTags       []string `json:"hub_tags"`
+	HubExprs      []string `json:"hub_exprs"`
+	SampleSize    int      `json:"sample_size"`
+	Seed          int64    `json:"seed"`
+	ConstraintRequest
+	Limit int `json:"limit"`
+}
+
+type ClosureResponse struct {
+	Error        string
+	Hubs         []string
+	Pairs        int
+	Disconnected [][2]string
+	Longer       []DetourStats
+	Unchanged    int
+}
+
+type DetourStats struct {
+	From   string        `json:"from"`
+	To     string        `json:"to"`
+	Before int           `json:"before"`
+	After  int           `json:"after"`
+	Route  []system.Data `json:"route"`
 }
 
 type ListResponse struct {
@@ -89,6 +523,25 @@ func (a *App) writeError(w http.ResponseWriter, estr string, code int) {
 	}
 }
 
+func (a *App) writeBlocked(w http.ResponseWriter, estr string, cut *path.Cut) {
+	resp := RouteResponse{
+		Error: estr,
+		Blocked: &BlockedStats{
+			AvoidSystems: a.universe.SystemNames(cut.Avoids),
+			AvoidTags:    a.universe.TagNames(cut.AvoidTags),
+			AvoidExprs:   universe.ExprNames(cut.AvoidExprs),
+			Jumps:        cut.Jumps,
+		},
+	}
+
+	encoder := json.NewEncoder(w)
+
+	w.WriteHeader(404)
+	if err := encoder.Encode(resp); err != nil {
+		panic(err)
+	}
+}
+
 func (a *App) handleGetRoute(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
@@ -102,195 +555,1600 @@ func (a *App) handleGetRoute(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := a.applyProfile(&req); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
 	if len(req.FromSystems) < 1 {
 		a.writeError(w, "must specify at least one source system", 400)
 		return
 	}
 
-	if req.ToSystem != "" && req.ToTag != "" {
-		a.writeError(w, "cannot provide both target system and tag", 400)
+	numTargets := 0
+	for _, t := range []string{req.ToSystem, req.ToTag, req.ToExpr} {
+		if t != "" {
+			numTargets++
+		}
+	}
+
+	if numTargets > 1 {
+		a.writeError(w, "cannot provide more than one of target system, tag, and expression", 400)
 		return
 	}
 
-	if req.ToSystem == "" && req.ToTag == "" {
-		a.writeError(w, "must provide either target system or tag", 400)
+	if numTargets == 0 {
+		a.writeError(w, "must provide either target system, tag, or expression", 400)
 		return
 	}
 
-	fromIDs := make([]int, len(req.FromSystems))
-	for i, sname := range req.FromSystems {
-		fromIDs[i] = a.systems[strings.ToLower(sname)]
+	fromIDs, err := a.universe.SystemIDs(req.FromSystems)
+	if err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
 	}
-	avoidIDs := make([]int, len(req.AvoidSystems))
-	for i, aname := range req.AvoidSystems {
-		avoidIDs[i] = a.systems[strings.ToLower(aname)]
+	avoidIDs, err := a.universe.SystemIDs(req.AvoidSystems)
+	if err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
 	}
-	avoidTagIDs := make([]int, len(req.AvoidTags))
-	for i, tname := range req.AvoidTags {
-		avoidTagIDs[i] = a.tags[tname]
+	avoidTagIDs, err := a.universe.TagIDs(req.AvoidTags)
+	if err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
 	}
-	preferNotTagIDs := make([]int, len(req.PreferNotTags))
-	for i, tname := range req.PreferNotTags {
-		preferNotTagIDs[i] = a.tags[tname]
+	preferNotTagIDs, err := a.universe.TagIDs(req.PreferNotTags)
+	if err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
 	}
 
-	var routes [][]int
-	var err error
-
-	switch {
-	case req.ToSystem != "":
-		toID := a.systems[strings.ToLower(req.ToSystem)]
-		if len(fromIDs) == 1 {
-			routes, err = a.pathfinder.FindShortestRoutes(fromIDs[0], toID, avoidIDs, avoidTagIDs, preferNotTagIDs)
-		} else {
-			routes, err = a.pathfinder.FindAllShortestRoutes(fromIDs, toID, avoidIDs, avoidTagIDs, preferNotTagIDs)
-		}
-	case req.ToTag != "":
-		toID := a.tags[req.ToTag]
-		if len(fromIDs) == 1 {
-			routes, err = a.pathfinder.FindShortestRoutesToTag(fromIDs[0], toID, avoidIDs, avoidTagIDs, preferNotTagIDs)
-		} else {
-			routes, err = a.pathfinder.FindAllShortestRoutesToTag(fromIDs, toID, avoidIDs, avoidTagIDs, preferNotTagIDs)
-		}
+	avoidExprs, err := a.universe.ParseExprs(req.AvoidExprs)
+	if err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
 	}
-
+	preferNotExprs, err := a.universe.ParseExprs(req.PreferNotExprs)
 	if err != nil {
-		a.writeError(w, errors.Wrap(err, "could not find a viable route").Error(), 404)
+		a.writeError(w, err.Error(), 400)
 		return
 	}
 
-	resp := RouteResponse{
-		Routes: make([][]system.Data, len(routes)),
+	budgets, err := a.universe.Budgets(req.MaxTagJumps)
+	if err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
 	}
 
-	for i, route := range routes {
-		resp.Routes[i] = a.GetNiceRoute(route)
+	avoidGates, err := a.universe.GateIDs(req.AvoidGates)
+	if err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
 	}
 
-	encoder := json.NewEncoder(w)
-	w.WriteHeader(200)
-	if err := encoder.Encode(resp); err != nil {
-		panic(err)
+	objective, err := path.ParseRouteObjective(req.Objective)
+	if err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
 	}
-}
 
-func (a *App) handleListTags(w http.ResponseWriter, r *http.Request) {
-	resp := ListResponse{
-		Items: make([]string, 0, len(a.tags)),
+	priorities, err := a.parsePriorities(req.Priorities)
+	if err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
 	}
 
-	for k := range a.tags {
-		resp.Items = append(resp.Items, k)
+	measures, err := a.parseMeasures(req.Pareto)
+	if err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
 	}
 
-	w.Header().Add("Content-type", "application/json")
-
-	encoder := json.NewEncoder(w)
-
-	w.WriteHeader(200)
-	if err := encoder.Encode(resp); err != nil {
-		panic(err)
+	q := path.Query{
+		Sources:        fromIDs,
+		Avoids:         avoidIDs,
+		AvoidGates:     avoidGates,
+		AvoidTags:      avoidTagIDs,
+		AvoidExprs:     avoidExprs,
+		PreferNotTags:  preferNotTagIDs,
+		PreferNotExprs: preferNotExprs,
+		MaxJumps:       req.MaxJumps,
+		Budgets:        budgets,
+		Limit:          req.Limit,
+		UseBridges:     req.UseBridges,
+		BridgeOwners:   req.BridgeOwners,
+		BridgeCost:     req.BridgeCost,
+		Priorities:     priorities,
+		Objective:      objective,
+		Travel:         travelParams(req.AlignTime, req.WarpSpeed, req.JumpTime, req.GateCloak),
 	}
-}
 
-func (a *App) handleListSystems(w http.ResponseWriter, r *http.Request) {
-	resp := ListResponse{
-		Items: make([]string, 0, len(a.systems)),
+	if !req.IgnoreWormholes {
+		shipSize, err := wormhole.ParseSize(req.ShipSize)
+		if err != nil {
+			a.writeError(w, err.Error(), 400)
+			return
+		}
+		q.Wormholes = a.wormholeEdges(shipSize, req.ShipMass)
 	}
 
-	for _, sd := range a.reverseSystemData {
-		resp.Items = append(resp.Items, sd.Name)
+	applied, err := a.applyIntel(&q, req.Intel, req.IntelPenalty)
+	if err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
 	}
 
-	w.Header().Add("Content-type", "application/json")
+	dangerAvoided, err := a.applyDanger(&q, req.DangerWeight, req.MaxDanger)
+	if err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
 
-	encoder := json.NewEncoder(w)
+	switch {
+	case req.ToSystem != "":
+		q.Targets, err = a.universe.SystemIDs([]string{req.ToSystem})
+	case req.ToTag != "":
+		q.TargetTags, err = a.universe.TagIDs([]string{req.ToTag})
+	case req.ToExpr != "":
+		q.TargetExprs, err = a.universe.ParseExprs([]string{req.ToExpr})
+	}
+	if err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
 
-	w.WriteHeader(200)
-	if err := encoder.Encode(resp); err != nil {
-		panic(err)
+	var routes []path.Route
+	var pareto []path.ParetoRoute
+	switch {
+	case len(measures) > 0:
+		pareto, err = a.pathfinder.ParetoRoutes(r.Context(), q, measures)
+		for _, pr := range pareto {
+			routes = append(routes, pr.Route)
+		}
+	case req.Limit > 0 && req.ToSystem == "":
+		routes, err = a.pathfinder.NearestRoutes(r.Context(), q)
+	default:
+		routes, err = a.pathfinder.Route(r.Context(), q)
 	}
-}
 
-func (a *App) Serve() error {
-	http.HandleFunc("/get_routes", a.handleGetRoute)
-	http.HandleFunc("/list_tags", a.handleListTags)
-	http.HandleFunc("/list_systems", a.handleListSystems)
+	if stderr.Is(err, path.ErrBadQuery) {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
 
-	return http.ListenAndServe(a.listen, nil)
-}
+	if stderr.Is(err, path.ErrSearchTooLarge) {
+		a.writeError(w, err.Error(), 422)
+		return
+	}
 
-func (a *App) GetNiceRoute(route []int) []system.Data {
-	niceRoute := make([]system.Data, len(route))
-	for j, id := range route {
-		niceRoute[j] = a.reverseSystemData[id]
+	if stderr.Is(err, path.ErrNoCoordinates) {
+		a.writeError(w, err.Error(), 501)
+		return
 	}
 
-	return niceRoute
-}
+	var noRoute *path.NoRouteError
+	if stderr.As(err, &noRoute) && noRoute.Cut != nil {
+		a.writeBlocked(w, errors.Wrap(err, "could not find a viable route").Error(), noRoute.Cut)
+		return
+	}
 
-func (a *App) loadSystemData() error {
-	f, err := os.Open(a.systemDataFile)
 	if err != nil {
-		return errors.Wrap(err, "could not open system data")
+		a.writeError(w, errors.Wrap(err, "could not find a viable route").Error(), 404)
+		return
 	}
-	defer deferutil.CheckDefer(f.Close)
 
-	dataContents := DataContents{}
+	resp := RouteResponse{
+		Routes: make([][]system.Data, len(routes)),
+		Stats:  make([]RouteStats, len(routes)),
+		Intel:  applied,
 
-	decoder := yaml.NewDecoder(f)
-	if err := decoder.Decode(&dataContents); err != nil {
-		return errors.Wrap(err, "could not yaml decode system data")
+		DangerAvoided: a.universe.SystemNames(dangerAvoided),
 	}
 
-	a.rawDataContents = dataContents
-
-	return nil
-}
+	for i, route := range routes {
+		resp.Routes[i] = a.GetNiceRoute(route.Systems)
+		resp.Stats[i] = a.GetRouteStats(route)
+		resp.Stats[i].Intel = a.routeIntel(route, applied)
+		a.addDangerStats(&resp.Stats[i], route)
 
-func (a *App) preparePathFinder() error {
-	numSystems := len(a.rawDataContents.SystemData)
-	graph := make([][]int, numSystems)
-	graphTags := make([][]bool, numSystems)
-
-	// first pass
-	for _, sd := range a.rawDataContents.SystemData {
-		// populate system name lookups
-		a.systems[strings.ToLower(sd.Name)] = sd.ID
-		a.reverseSystems[sd.ID] = sd.Name
-		a.reverseSystemData[sd.ID] = sd
-		a.systemSec[sd.ID] = sd.SecStatus
-
-		// populate tag name lookups
-		for _, t := range sd.Tags {
-			var tid int
-			var ok bool
-			if _, ok = a.tags[t]; !ok {
-				tid = len(a.tags)
-				a.tags[t] = tid
-				a.reverseTags[tid] = t
+		if pareto != nil {
+			resp.Stats[i].Tradeoff = pareto[i].Tradeoff(measures)
+			resp.Stats[i].Measures = make(map[string]float64, len(measures))
+			for j, m := range measures {
+				resp.Stats[i].Measures[m.Name] = pareto[i].Measures[j]
 			}
 		}
 	}
 
-	// second pass
-	for _, sd := range a.rawDataContents.SystemData {
-		graph[sd.ID] = make([]int, len(sd.Destinations))
-		graphTags[sd.ID] = make([]bool, len(a.tags))
-
-		// set destinations
-		for i, d := range sd.Destinations {
-			graph[sd.ID][i] = a.systems[strings.ToLower(d)]
-		}
-
-		// set tags
-		for _, t := range sd.Tags {
-			tid := a.tags[t]
-			graphTags[sd.ID][tid] = true
-		}
-	}
+	encoder := json.NewEncoder(w)
+	w.WriteHeader(200)
+	if err := encoder.Encode(resp); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) handleGetJumpRoute(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	w.Header().Add("Content-type", "application/json")
+
+	req := JumpRouteRequest{}
+	decoder := json.NewDecoder(r.Body)
+
+	if err := decoder.Decode(&req); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if err := a.applyJumpProfile(&req); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if len(req.FromSystems) < 1 {
+		a.writeError(w, "must specify at least one source system", 400)
+		return
+	}
+
+	if req.ToSystem == "" {
+		a.writeError(w, "must provide a target system", 400)
+		return
+	}
+
+	if req.ShipClass == "" {
+		req.ShipClass = "capital"
+	}
+
+	rangeLY := req.RangeLY
+	if rangeLY == 0 {
+		var err error
+		if rangeLY, err = a.jumpRanges.Range(req.ShipClass); err != nil {
+			a.writeError(w, err.Error(), 400)
+			return
+		}
+	}
+
+	hull, err := jump.HullFor(req.ShipClass)
+	if err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+	calc := jump.NewCalculator(hull, jump.Skills{
+		FuelConservation: req.FuelConservation,
+		HullSkill:        req.HullSkill,
+	})
+
+	objective, err := path.ParseJumpObjective(req.Objective)
+	if err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	fromIDs, err := a.universe.SystemIDs(req.FromSystems)
+	if err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+	avoidIDs, err := a.universe.SystemIDs(req.AvoidSystems)
+	if err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+	avoidTagIDs, err := a.universe.TagIDs(req.AvoidTags)
+	if err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+	toIDs, err := a.universe.SystemIDs([]string{req.ToSystem})
+	if err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	route, err := a.pathfinder.FindJumpRoute(r.Context(), path.JumpQuery{
+		Sources:          fromIDs,
+		Targets:          toIDs,
+		RangeLY:          rangeLY,
+		Avoids:           avoidIDs,
+		AvoidTags:        avoidTagIDs,
+		Objective:        objective,
+		FatigueReduction: hull.FatigueReduction,
+	})
+	if stderr.Is(err, path.ErrBadQuery) {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if stderr.Is(err, path.ErrNoCoordinates) {
+		a.writeError(w, err.Error(), 501)
+		return
+	}
+
+	if err != nil {
+		a.writeError(w, errors.Wrap(err, "could not find a viable jump route").Error(), 404)
+		return
+	}
+
+	plan := calc.Plan(route.Legs, time.Duration(req.FatigueMinutes*float64(time.Minute)))
+
+	resp := JumpRouteResponse{
+		Route: a.GetNiceRoute(route.Systems),
+		Stats: &JumpRouteStats{
+			Source:              a.universe.SystemName(route.Source),
+			Jumps:               route.Jumps,
+			LegsLY:              route.Legs,
+			DistanceLY:          route.DistanceLY,
+			Legs:                make([]JumpLegStats, len(plan.Legs)),
+			Isotopes:            plan.Isotopes,
+			WaitMinutes:         plan.Wait.Minutes(),
+			FinalFatigueMinutes: plan.FinalFatigue.Minutes(),
+		},
+	}
+
+	for i, leg := range plan.Legs {
+		resp.Stats.Legs[i] = JumpLegStats{
+			From:                a.universe.SystemName(route.Systems[i]),
+			To:                  a.universe.SystemName(route.Systems[i+1]),
+			DistanceLY:          leg.DistanceLY,
+			EffectiveLY:         leg.EffectiveLY,
+			Isotopes:            leg.Isotopes,
+			WaitMinutes:         leg.Wait.Minutes(),
+			FatigueMinutes:      leg.FatigueAfter.Minutes(),
+			ReactivationMinutes: leg.Reactivation.Minutes(),
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	w.WriteHeader(200)
+	if err := encoder.Encode(resp); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) handleListTags(w http.ResponseWriter, r *http.Request) {
+	resp := ListResponse{
+		Items: a.universe.Tags(),
+	}
+
+	w.Header().Add("Content-type", "application/json")
+
+	encoder := json.NewEncoder(w)
+
+	w.WriteHeader(200)
+	if err := encoder.Encode(resp); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) handleListSystems(w http.ResponseWriter, r *http.Request) {
+	resp := ListResponse{
+		Items: make([]string, 0, len(a.universe.Systems())),
+	}
+
+	for _, sd := range a.universe.Systems() {
+		resp.Items = append(resp.Items, sd.Name)
+	}
+
+	w.Header().Add("Content-type", "application/json")
+
+	encoder := json.NewEncoder(w)
+
+	w.WriteHeader(200)
+	if err := encoder.Encode(resp); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) writeWormholes(w http.ResponseWriter, holes []wormhole.Wormhole) {
+	now := time.Now()
+
+	resp := WormholeResponse{
+		Wormholes: make([]WormholeStats, len(holes)),
+	}
+
+	for i, wh := range holes {
+		resp.Wormholes[i] = WormholeStats{
+			ID:               wh.ID,
+			From:             wh.From,
+			To:               wh.To,
+			Expires:          wh.Expires,
+			ExpiresInMinutes: wh.Remaining(now).Minutes(),
+			MaxShipSize:      wh.MaxShipSize.String(),
+			MaxShipMass:      wh.MaxShipMass,
+			Note:             wh.Note,
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+
+	w.WriteHeader(200)
+	if err := encoder.Encode(resp); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) handleAddWormhole(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	w.Header().Add("Content-type", "application/json")
+
+	req := WormholeRequest{}
+	decoder := json.NewDecoder(r.Body)
+
+	if err := decoder.Decode(&req); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	from, ok := a.universe.SystemID(req.From)
+	if !ok {
+		a.writeError(w, "unknown system: "+req.From, 400)
+		return
+	}
+
+	to, ok := a.universe.SystemID(req.To)
+	if !ok {
+		a.writeError(w, "unknown system: "+req.To, 400)
+		return
+	}
+
+	expires := req.ExpiresAt
+	if req.ExpiresMinutes > 0 {
+		expires = time.Now().Add(time.Duration(req.ExpiresMinutes * float64(time.Minute)))
+	}
+
+	size, err := wormhole.ParseSize(req.MaxShipSize)
+	if err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	wh, err := a.wormholes.Add(wormhole.Wormhole{
+		From:        a.universe.SystemName(from),
+		To:          a.universe.SystemName(to),
+		Expires:     expires,
+		MaxShipSize: size,
+		MaxShipMass: req.MaxShipMass,
+		Note:        req.Note,
+	})
+	if stderr.Is(err, wormhole.ErrBadWormhole) {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if err != nil {
+		a.writeError(w, errors.Wrap(err, "could not save wormhole").Error(), 500)
+		return
+	}
+
+	a.writeWormholes(w, []wormhole.Wormhole{wh})
+}
+
+func (a *App) handleRemoveWormhole(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	w.Header().Add("Content-type", "application/json")
+
+	req := WormholeRemoveRequest{}
+	decoder := json.NewDecoder(r.Body)
+
+	if err := decoder.Decode(&req); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	found, err := a.wormholes.Remove(req.ID)
+	if err != nil {
+		a.writeError(w, errors.Wrap(err, "could not save wormholes").Error(), 500)
+		return
+	}
+
+	if !found {
+		a.writeError(w, "unknown wormhole: "+req.ID, 404)
+		return
+	}
+
+	a.writeWormholes(w, a.wormholes.Live())
+}
+
+func (a *App) handleListWormholes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-type", "application/json")
+
+	a.writeWormholes(w, a.wormholes.Live())
+}
+
+func (a *App) intelStats(r intel.Report, now time.Time) IntelStats {
+	return IntelStats{
+		ID:               r.ID,
+		System:           r.System,
+		Reported:         r.Reported,
+		AgeMinutes:       now.Sub(r.Reported).Minutes(),
+		ExpiresInMinutes: r.Expires.Sub(now).Minutes(),
+		Weight:           r.Weight(now),
+		Count:            r.Count,
+		Reporter:         r.Reporter,
+		Note:             r.Note,
+	}
+}
+
+func (a *App) writeIntel(w http.ResponseWriter, reports []intel.Report) {
+	now := time.Now()
+
+	resp := IntelResponse{
+		Reports: make([]IntelStats, len(reports)),
+	}
+
+	for i, r := range reports {
+		resp.Reports[i] = a.intelStats(r, now)
+	}
+
+	encoder := json.NewEncoder(w)
+
+	w.WriteHeader(200)
+	if err := encoder.Encode(resp); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) handleReportIntel(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	w.Header().Add("Content-type", "application/json")
+
+	req := IntelRequest{}
+	decoder := json.NewDecoder(r.Body)
+
+	if err := decoder.Decode(&req); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	sys, ok := a.universe.SystemID(req.System)
+	if !ok {
+		a.writeError(w, "unknown system: "+req.System, 400)
+		return
+	}
+
+	if req.TTLMinutes == 0 {
+		req.TTLMinutes = defaultIntelTTLMinutes
+	}
+
+	report, err := a.intel.Add(intel.Report{
+		System:   a.universe.SystemName(sys),
+		Count:    req.Count,
+		Reporter: req.Reporter,
+		Note:     req.Note,
+	}, time.Duration(req.TTLMinutes*float64(time.Minute)))
+	if stderr.Is(err, intel.ErrBadReport) {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if err != nil {
+		a.writeError(w, errors.Wrap(err, "could not save intel report").Error(), 500)
+		return
+	}
+
+	a.writeIntel(w, []intel.Report{report})
+}
+
+func (a *App) handleRemoveIntel(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	w.Header().Add("Content-type", "application/json")
+
+	req := IntelRemoveRequest{}
+	decoder := json.NewDecoder(r.Body)
+
+	if err := decoder.Decode(&req); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	found, err := a.intel.Remove(req.ID)
+	if err != nil {
+		a.writeError(w, errors.Wrap(err, "could not save intel reports").Error(), 500)
+		return
+	}
+
+	if !found {
+		a.writeError(w, "unknown intel report: "+req.ID, 404)
+		return
+	}
+
+	a.writeIntel(w, a.intel.Live())
+}
+
+func (a *App) handleListIntel(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-type", "application/json")
+
+	a.writeIntel(w, a.intel.Live())
+}
+
+func (a *App) handleListProfiles(w http.ResponseWriter, r *http.Request) {
+	resp := ListResponse{
+		Items: a.profiles.Names(),
+	}
+
+	w.Header().Add("Content-type", "application/json")
+
+	encoder := json.NewEncoder(w)
+
+	w.WriteHeader(200)
+	if err := encoder.Encode(resp); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) handleRendezvous(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	w.Header().Add("Content-type", "application/json")
+
+	req := RendezvousRequest{}
+	decoder := json.NewDecoder(r.Body)
+
+	if err := decoder.Decode(&req); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	objective, err := path.ParseRendezvousObjective(req.Objective)
+	if err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	constraints, err := a.parseConstraints(req.ConstraintRequest)
+	if err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	members := make([]path.Member, len(req.Members))
+	for i, m := range req.Members {
+		sys, ok := a.universe.SystemID(m.System)
+		if !ok {
+			a.writeError(w, "unknown system: "+m.System, 400)
+			return
+		}
+		members[i] = path.Member{System: sys, Weight: m.Weight}
+	}
+
+	found, err := a.pathfinder.FindRendezvous(r.Context(), path.RendezvousQuery{
+		Members:     members,
+		Constraints: constraints,
+		Objective:   objective,
+		Limit:       req.Limit,
+	})
+	if stderr.Is(err, path.ErrBadQuery) {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if err != nil {
+		a.writeError(w, errors.Wrap(err, "could not find a rendezvous").Error(), 404)
+		return
+	}
+
+	resp := RendezvousResponse{
+		Rendezvous: make([]RendezvousStats, len(found)),
+	}
+
+	for i, rv := range found {
+		stats := RendezvousStats{
+			System:      a.universe.SystemName(rv.System),
+			MaxJumps:    rv.MaxJumps,
+			TotalJumps:  rv.TotalJumps,
+			WeightedMax: rv.WeightedMax,
+			WeightedSum: rv.WeightedSum,
+			Routes:      make([][]system.Data, len(rv.Routes)),
+			Stats:       make([]RouteStats, len(rv.Routes)),
+		}
+
+		for j, route := range rv.Routes {
+			stats.Routes[j] = a.GetNiceRoute(route.Systems)
+			stats.Stats[j] = a.GetRouteStats(route)
+		}
+
+		resp.Rendezvous[i] = stats
+	}
+
+	encoder := json.NewEncoder(w)
+	w.WriteHeader(200)
+	if err := encoder.Encode(resp); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) handleStaging(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	w.Header().Add("Content-type", "application/json")
+
+	req := StagingRequest{}
+	decoder := json.NewDecoder(r.Body)
+
+	if err := decoder.Decode(&req); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	q := path.StagingQuery{
+		Range: req.Range,
+		Limit: req.Limit,
+	}
+
+	var err error
+	if q.Targets, err = a.universe.SystemIDs(req.Targets); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if q.TargetTags, err = a.universe.TagIDs(req.TargetTags); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if q.TargetExprs, err = a.universe.ParseExprs(req.TargetExprs); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if q.CandidateTags, err = a.universe.TagIDs(req.CandidateTags); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if q.CandidateExprs, err = a.universe.ParseExprs(req.CandidateExprs); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if q.Constraints, err = a.parseConstraints(req.ConstraintRequest); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	found, err := a.pathfinder.RankStaging(r.Context(), q)
+	if stderr.Is(err, path.ErrBadQuery) {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if err != nil {
+		a.writeError(w, errors.Wrap(err, "could not rank staging systems").Error(), 404)
+		return
+	}
+
+	resp := StagingResponse{
+		Staging: make([]StagingStats, len(found)),
+	}
+
+	for i, s := range found {
+		stats := StagingStats{
+			System:       a.universe.SystemName(s.System),
+			Coverage:     len(s.Covered),
+			Targets:      s.Targets,
+			AverageJumps: s.AverageJumps,
+			Covered:      make([]ReachStats, len(s.Covered)),
+		}
+
+		for j, c := range s.Covered {
+			stats.Covered[j] = ReachStats{System: a.universe.SystemName(c.System), Jumps: c.Jumps}
+		}
+
+		resp.Staging[i] = stats
+	}
+
+	encoder := json.NewEncoder(w)
+	w.WriteHeader(200)
+	if err := encoder.Encode(resp); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) handleRoam(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	w.Header().Add("Content-type", "application/json")
+
+	req := RoamRequest{}
+	decoder := json.NewDecoder(r.Body)
+
+	if err := decoder.Decode(&req); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	home, ok := a.universe.SystemID(req.Home)
+	if !ok {
+		a.writeError(w, "unknown system: "+req.Home, 400)
+		return
+	}
+
+	q := path.RoamQuery{
+		Home:     home,
+		Systems:  req.Systems,
+		MaxJumps: req.MaxJumps,
+		Limit:    req.Limit,
+		Seed:     req.Seed,
+	}
+
+	var err error
+	if q.PreferTags, err = a.universe.TagIDs(req.PreferTags); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if q.PreferExprs, err = a.universe.ParseExprs(req.PreferExprs); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if q.Constraints, err = a.parseConstraints(req.ConstraintRequest); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	roams, err := a.pathfinder.FindRoams(r.Context(), q)
+	if stderr.Is(err, path.ErrBadQuery) {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if err != nil {
+		a.writeError(w, errors.Wrap(err, "could not find a roam").Error(), 404)
+		return
+	}
+
+	resp := RoamResponse{
+		Roams: make([]RoamStats, len(roams)),
+	}
+
+	for i, roam := range roams {
+		resp.Roams[i] = RoamStats{
+			Route:     a.GetNiceRoute(roam.Route.Systems),
+			Preferred: roam.Preferred,
+			Stats:     a.GetRouteStats(roam.Route),
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	w.WriteHeader(200)
+	if err := encoder.Encode(resp); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) handleSweep(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	w.Header().Add("Content-type", "application/json")
+
+	req := SweepRequest{}
+	decoder := json.NewDecoder(r.Body)
+
+	if err := decoder.Decode(&req); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	start, ok := a.universe.SystemID(req.Start)
+	if !ok {
+		a.writeError(w, "unknown system: "+req.Start, 400)
+		return
+	}
+
+	q := path.SweepQuery{
+		Start:  start,
+		Return: req.Return,
+	}
+
+	var err error
+	if q.Targets, err = a.universe.SystemIDs(req.Targets); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if q.TargetTags, err = a.universe.TagIDs(req.TargetTags); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if q.TargetExprs, err = a.universe.ParseExprs(req.TargetExprs); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if q.Constraints, err = a.parseConstraints(req.ConstraintRequest); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	sweep, err := a.pathfinder.PlanSweep(r.Context(), q)
+	if stderr.Is(err, path.ErrBadQuery) {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if err != nil {
+		a.writeError(w, errors.Wrap(err, "could not plan a sweep").Error(), 404)
+		return
+	}
+
+	resp := SweepResponse{
+		Order:       a.universe.SystemNames(sweep.Order),
+		Route:       a.GetNiceRoute(sweep.Route.Systems),
+		Stats:       a.GetRouteStats(sweep.Route),
+		Unreachable: a.universe.SystemNames(sweep.Unreachable),
+	}
+
+	encoder := json.NewEncoder(w)
+	w.WriteHeader(200)
+	if err := encoder.Encode(resp); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) handleCourier(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	w.Header().Add("Content-type", "application/json")
+
+	req := CourierRequest{}
+	decoder := json.NewDecoder(r.Body)
+
+	if err := decoder.Decode(&req); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	contracts := req.Contracts
+	if req.ContractsCSV != "" {
+		fromCSV, err := courier.ReadCSV(strings.NewReader(req.ContractsCSV))
+		if err != nil {
+			a.writeError(w, err.Error(), 400)
+			return
+		}
+		contracts = fromCSV
+	} else if err := courier.Validate(contracts); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	start, ok := a.universe.SystemID(req.Start)
+	if !ok {
+		a.writeError(w, "unknown system: "+req.Start, 400)
+		return
+	}
+
+	q := path.CourierQuery{
+		Start:       start,
+		Deliveries:  make([]path.Delivery, len(contracts)),
+		HighSecOnly: req.HighSecOnly,
+	}
+
+	for i, c := range contracts {
+		ids, err := a.universe.SystemIDs([]string{c.Pickup, c.Dropoff})
+		if err != nil {
+			a.writeError(w, errors.Wrapf(err, "bad contract %s", c.ID).Error(), 400)
+			return
+		}
+		q.Deliveries[i] = path.Delivery{Pickup: ids[0], Dropoff: ids[1]}
+	}
+
+	var err error
+	if q.Constraints, err = a.parseConstraints(req.ConstraintRequest); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	plan, err := a.pathfinder.PlanCourier(r.Context(), q)
+	if stderr.Is(err, path.ErrBadQuery) {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if err != nil {
+		a.writeError(w, errors.Wrap(err, "could not plan a courier route").Error(), 404)
+		return
+	}
+
+	resp := CourierResponse{
+		Stops: make([]CourierStop, len(plan.Stops)),
+		Route: a.GetNiceRoute(plan.Route.Systems),
+		Stats: a.GetRouteStats(plan.Route),
+	}
+
+	for i, stop := range plan.Stops {
+		cs := CourierStop{
+			System:   a.universe.SystemName(stop.System),
+			Pickups:  []string{},
+			Dropoffs: []string{},
+		}
+		for _, d := range stop.Pickups {
+			cs.Pickups = append(cs.Pickups, contracts[d].ID)
+		}
+		for _, d := range stop.Dropoffs {
+			cs.Dropoffs = append(cs.Dropoffs, contracts[d].ID)
+		}
+		resp.Stops[i] = cs
+	}
 
-	a.pathfinder = path.NewFinder(graph, graphTags)
+	encoder := json.NewEncoder(w)
+	w.WriteHeader(200)
+	if err := encoder.Encode(resp); err != nil {
+		panic(err)
+	}
+}
+
+// defaultHubs are the trade hubs checked when a closure request names no hubs.
+var defaultHubs = []string{"Jita", "Amarr", "Dodixie", "Rens", "Hek"}
+
+func (a *App) handleClosure(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	w.Header().Add("Content-type", "application/json")
+
+	req := ClosureRequest{}
+	decoder := json.NewDecoder(r.Body)
+
+	if err := decoder.Decode(&req); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if len(req.Hubs) == 0 && len(req.HubTags) == 0 && len(req.HubExprs) == 0 {
+		req.Hubs = defaultHubs
+	}
+
+	q := path.ClosureQuery{
+		SampleSize: req.SampleSize,
+		Seed:       req.Seed,
+		Limit:      req.Limit,
+	}
+
+	var err error
+	if q.Closed, err = a.universe.SystemIDs(req.ClosedSystems); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if q.ClosedGates, err = a.universe.GateIDs(req.ClosedGates); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if q.Hubs, err = a.universe.SystemIDs(req.Hubs); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if q.HubTags, err = a.universe.TagIDs(req.HubTags); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if q.HubExprs, err = a.universe.ParseExprs(req.HubExprs); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if q.Constraints, err = a.parseConstraints(req.ConstraintRequest); err != nil {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	impact, err := a.pathfinder.AnalyzeClosure(r.Context(), q)
+	if stderr.Is(err, path.ErrBadQuery) {
+		a.writeError(w, err.Error(), 400)
+		return
+	}
+
+	if err != nil {
+		a.writeError(w, errors.Wrap(err, "could not analyze the closure").Error(), 500)
+		return
+	}
+
+	resp := ClosureResponse{
+		Hubs:         a.universe.SystemNames(impact.Hubs),
+		Pairs:        impact.Pairs,
+		Disconnected: make([][2]string, len(impact.Disconnected)),
+		Longer:       make([]DetourStats, len(impact.Longer)),
+		Unchanged:    impact.Unchanged,
+	}
+
+	for i, p := range impact.Disconnected {
+		resp.Disconnected[i] = [2]string{a.universe.SystemName(p.From), a.universe.SystemName(p.To)}
+	}
+
+	for i, d := range impact.Longer {
+		resp.Longer[i] = DetourStats{
+			From:   a.universe.SystemName(d.From),
+			To:     a.universe.SystemName(d.To),
+			Before: d.Before,
+			After:  d.After,
+			Route:  a.GetNiceRoute(d.Route.Systems),
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	w.WriteHeader(200)
+	if err := encoder.Encode(resp); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) Serve() error {
+	http.HandleFunc("/get_routes", a.handleGetRoute)
+	http.HandleFunc("/get_jump_routes", a.handleGetJumpRoute)
+	http.HandleFunc("/list_tags", a.handleListTags)
+	http.HandleFunc("/list_systems", a.handleListSystems)
+	http.HandleFunc("/list_profiles", a.handleListProfiles)
+	http.HandleFunc("/rendezvous", a.handleRendezvous)
+	http.HandleFunc("/staging", a.handleStaging)
+	http.HandleFunc("/roam", a.handleRoam)
+	http.HandleFunc("/sweep", a.handleSweep)
+	http.HandleFunc("/courier", a.handleCourier)
+	http.HandleFunc("/closure", a.handleClosure)
+	http.HandleFunc("/add_wormhole", a.handleAddWormhole)
+	http.HandleFunc("/remove_wormhole", a.handleRemoveWormhole)
+	http.HandleFunc("/list_wormholes", a.handleListWormholes)
+	http.HandleFunc("/report_intel", a.handleReportIntel)
+	http.HandleFunc("/remove_intel", a.handleRemoveIntel)
+	http.HandleFunc("/list_intel", a.handleListIntel)
+
+	return http.ListenAndServe(a.listen, nil)
+}
+
+func (a *App) GetNiceRoute(route []int) []system.Data {
+	niceRoute := make([]system.Data, len(route))
+	for j, id := range route {
+		niceRoute[j] = a.universe.System(id)
+	}
+
+	return niceRoute
+}
+
+func (a *App) GetRouteStats(route path.Route) RouteStats {
+	now := time.Now()
+
+	legs := make([]RouteLeg, len(route.Edges))
+	for i, e := range route.Edges {
+		legs[i] = RouteLeg{
+			From:  a.universe.SystemName(e.From),
+			To:    a.universe.SystemName(e.To),
+			Kind:  e.Kind.String(),
+			Label: e.Label,
+			Gate:  e.Gate,
+		}
+
+		if e.Kind != path.Wormhole {
+			continue
+		}
+
+		if wh, ok := a.wormholes.Get(e.Label); ok {
+			legs[i].ExpiresInMinutes = wh.Remaining(now).Minutes()
+			legs[i].Note = wh.Note
+		}
+	}
+
+	stats := RouteStats{
+		Source:    a.universe.SystemName(route.Source),
+		Jumps:     route.Jumps,
+		Cost:      route.Cost,
+		Legs:      legs,
+		SecCounts: route.SecCounts,
+		Tags:      a.universe.TagNames(route.Tags),
+		Violated:  append(a.universe.TagNames(route.Violated), universe.ExprNames(route.ViolatedExprs)...),
+		Relaxed:   append(a.universe.TagNames(route.Relaxed), universe.ExprNames(route.RelaxedExprs)...),
+
+		Priorities: route.PriorityCounts,
+	}
+
+	if a.pathfinder.HasGatePositions() {
+		stats.ETA = route.ETA.Seconds()
+		stats.WarpAU = route.WarpAU
+	}
+
+	return stats
+}
+
+func (a *App) parsePriorities(priorities []PriorityRequest) ([]path.Priority, error) {
+	parsed := make([]path.Priority, len(priorities))
+	for i, p := range priorities {
+		parsed[i].Maximize = p.Minimize != nil && !*p.Minimize
+
+		switch {
+		case p.Expr != "":
+			exprs, err := a.universe.ParseExprs([]string{p.Expr})
+			if err != nil {
+				return nil, err
+			}
+			parsed[i].Expr = exprs[0]
+		case p.Tag != "":
+			tid, ok := a.universe.TagID(p.Tag)
+			if !ok {
+				return nil, errors.Errorf("unknown tag %q", p.Tag)
+			}
+			parsed[i].Tag = tid
+		default:
+			return nil, errors.New("priority needs a tag or expression")
+		}
+	}
+
+	return parsed, nil
+}
+
+// parseMeasures turns tag names, tag expressions, and "danger" into the
+// measures of a Pareto route search.
+func (a *App) parseMeasures(names []string) ([]path.Measure, error) {
+	measures := make([]path.Measure, len(names))
+	for i, name := range names {
+		measures[i].Name = name
+
+		if tid, ok := a.universe.TagID(name); ok {
+			measures[i].Tag = tid
+			continue
+		}
+
+		if name == "danger" {
+			if a.danger == nil {
+				return nil, errors.New("no kill data loaded")
+			}
+
+			measures[i].Scores = map[int]float64{}
+			for sname, score := range a.danger.Scores().Danger {
+				if sys, ok := a.universe.SystemID(sname); ok {
+					measures[i].Scores[sys] = score
+				}
+			}
+			continue
+		}
+
+		exprs, err := a.universe.ParseExprs([]string{name})
+		if err != nil {
+			return nil, err
+		}
+		measures[i].Expr = exprs[0]
+	}
+
+	return measures, nil
+}
+
+// applyProfile fills in the request's unset options from its named profile,
+// and adds the profile's tags and expressions to the request's.
+func (a *App) applyProfile(req *RouteRequest) error {
+	if req.Profile == "" {
+		return nil
+	}
+
+	p, err := a.profiles.Get(req.Profile)
+	if err != nil {
+		return err
+	}
+
+	req.AvoidTags = append(req.AvoidTags, p.AvoidTags...)
+	req.AvoidExprs = append(req.AvoidExprs, p.AvoidExprs...)
+	req.PreferNotTags = append(req.PreferNotTags, p.PreferNotTags...)
+	req.PreferNotExprs = append(req.PreferNotExprs, p.PreferNotExprs...)
+
+	for tag, max := range p.MaxTagJumps {
+		if _, ok := req.MaxTagJumps[tag]; ok {
+			continue
+		}
+
+		if req.MaxTagJumps == nil {
+			req.MaxTagJumps = map[string]int{}
+		}
+		req.MaxTagJumps[tag] = max
+	}
+
+	req.UseBridges = req.UseBridges || p.UseBridges
+	if req.BridgeCost == 0 {
+		req.BridgeCost = p.BridgeCost
+	}
+	if req.Intel == "" {
+		req.Intel = p.Intel
+	}
+	if req.IntelPenalty == 0 {
+		req.IntelPenalty = p.IntelPenalty
+	}
+	if req.DangerWeight == 0 {
+		req.DangerWeight = p.DangerWeight
+	}
+	if req.MaxDanger == 0 {
+		req.MaxDanger = p.MaxDanger
+	}
+	if req.ShipSize == "" {
+		req.ShipSize = p.ShipSize
+	}
+	if req.ShipMass == 0 {
+		req.ShipMass = p.ShipMass
+	}
+	if req.Objective == "" {
+		req.Objective = p.Objective
+	}
+	if req.AlignTime == 0 {
+		req.AlignTime = p.AlignTime.Seconds()
+	}
+	if req.WarpSpeed == 0 {
+		req.WarpSpeed = p.WarpSpeed
+	}
+	if req.JumpTime == 0 {
+		req.JumpTime = p.JumpTime.Seconds()
+	}
+	if req.GateCloak == 0 {
+		req.GateCloak = p.GateCloak.Seconds()
+	}
+
+	return nil
+}
+
+func (a *App) applyJumpProfile(req *JumpRouteRequest) error {
+	if req.Profile == "" {
+		return nil
+	}
+
+	p, err := a.profiles.Get(req.Profile)
+	if err != nil {
+		return err
+	}
+
+	req.AvoidTags = append(req.AvoidTags, p.AvoidTags...)
+	if req.ShipClass == "" {
+		req.ShipClass = p.ShipClass
+	}
+	if req.RangeLY == 0 {
+		req.RangeLY = p.JumpRangeLY
+	}
 
 	return nil
 }
+
+// parseConstraints resolves the names in a constraint request, and adds the
+// open wormholes and the systems intel or danger rules out.
+func (a *App) parseConstraints(req ConstraintRequest) (path.Constraints, error) {
+	avoids, err := a.universe.SystemIDs(req.AvoidSystems)
+	if err != nil {
+		return path.Constraints{}, err
+	}
+
+	gates, err := a.universe.GateIDs(req.AvoidGates)
+	if err != nil {
+		return path.Constraints{}, err
+	}
+
+	tags, err := a.universe.TagIDs(req.AvoidTags)
+	if err != nil {
+		return path.Constraints{}, err
+	}
+
+	exprs, err := a.universe.ParseExprs(req.AvoidExprs)
+	if err != nil {
+		return path.Constraints{}, err
+	}
+
+	if req.Intel == "penalize" {
+		return path.Constraints{}, errors.New("fleet plans can avoid intel but not penalize it")
+	}
+
+	// applyIntel and applyDanger only add avoids here, since neither is asked
+	// for penalties
+	q := path.Query{Avoids: avoids}
+
+	if _, err := a.applyIntel(&q, req.Intel, 0); err != nil {
+		return path.Constraints{}, err
+	}
+
+	if _, err := a.applyDanger(&q, 0, req.MaxDanger); err != nil {
+		return path.Constraints{}, err
+	}
+
+	c := path.Constraints{
+		Avoids:       q.Avoids,
+		AvoidGates:   gates,
+		AvoidTags:    tags,
+		AvoidExprs:   exprs,
+		UseBridges:   req.UseBridges,
+		BridgeOwners: req.BridgeOwners,
+		Travel:       travelParams(req.AlignTime, req.WarpSpeed, req.JumpTime, req.GateCloak),
+	}
+
+	if !req.IgnoreWormholes {
+		shipSize, err := wormhole.ParseSize(req.ShipSize)
+		if err != nil {
+			return path.Constraints{}, err
+		}
+		c.Wormholes = a.wormholeEdges(shipSize, req.ShipMass)
+	}
+
+	return c, nil
+}
+
+// travelParams applies any non-zero overrides to the default travel model.
+func travelParams(align, warpSpeed, jumpTime, cloak float64) *path.Travel {
+	t := path.DefaultTravel
+	if align != 0 {
+		t.AlignTime = time.Duration(align * float64(time.Second))
+	}
+	if warpSpeed != 0 {
+		t.WarpSpeed = warpSpeed
+	}
+	if jumpTime != 0 {
+		t.JumpTime = time.Duration(jumpTime * float64(time.Second))
+	}
+	if cloak != 0 {
+		t.GateCloak = time.Duration(cloak * float64(time.Second))
+	}
+
+	return &t
+}
+
+const (
+	defaultIntelTTLMinutes = 15
+	defaultIntelPenalty    = 5
+)
+
+// applyIntel adds the live hostile reports to the query, either as avoided
+// systems or as penalties, and returns the reports it used.
+func (a *App) applyIntel(q *path.Query, mode string, maxPenalty int) ([]IntelStats, error) {
+	switch mode {
+	case "", "ignore":
+		return nil, nil
+	case "avoid", "penalize":
+	default:
+		return nil, errors.Errorf("unknown intel mode %q", mode)
+	}
+
+	if maxPenalty < 0 {
+		return nil, errors.New("negative intel penalty")
+	}
+
+	if maxPenalty == 0 {
+		maxPenalty = defaultIntelPenalty
+	}
+
+	now := time.Now()
+
+	var applied []IntelStats
+	for _, r := range a.intel.Live() {
+		sys, ok := a.universe.SystemID(r.System)
+		if !ok {
+			continue
+		}
+
+		stats := a.intelStats(r, now)
+
+		if mode == "avoid" {
+			q.Avoids = append(q.Avoids, sys)
+			stats.Effect = "avoided"
+		} else {
+			stats.Penalty = r.Penalty(now, maxPenalty)
+			stats.Effect = "penalized"
+
+			if q.Penalties == nil {
+				q.Penalties = map[int]int{}
+			}
+			if stats.Penalty > q.Penalties[sys] {
+				q.Penalties[sys] = stats.Penalty
+			}
+		}
+
+		applied = append(applied, stats)
+	}
+
+	return applied, nil
+}
+
+// applyDanger penalizes jumps into systems with recent kills, and avoids
+// systems whose danger score is over maxDanger. It returns the avoided systems.
+func (a *App) applyDanger(q *path.Query, weight, maxDanger float64) ([]int, error) {
+	if weight == 0 && maxDanger == 0 {
+		return nil, nil
+	}
+
+	if weight < 0 || maxDanger < 0 {
+		return nil, errors.New("negative danger weight or cutoff")
+	}
+
+	if a.danger == nil {
+		return nil, errors.New("no kill data loaded")
+	}
+
+	avoids, penalties := a.danger.Scores().Constraints(weight, maxDanger)
+
+	var avoided []int
+	for _, name := range avoids {
+		if sys, ok := a.universe.SystemID(name); ok {
+			q.Avoids = append(q.Avoids, sys)
+			avoided = append(avoided, sys)
+		}
+	}
+
+	for name, p := range penalties {
+		sys, ok := a.universe.SystemID(name)
+		if !ok {
+			continue
+		}
+
+		if q.Penalties == nil {
+			q.Penalties = map[int]int{}
+		}
+		q.Penalties[sys] += p
+	}
+
+	sort.Ints(avoided)
+
+	return avoided, nil
+}
+
+// addDangerStats totals the danger of the systems a route jumps into.
+func (a *App) addDangerStats(stats *RouteStats, route path.Route) {
+	if a.danger == nil {
+		return
+	}
+
+	scores := a.danger.Scores().Danger
+	for _, sys := range route.Systems[1:] {
+		name := a.universe.SystemName(sys)
+
+		score, ok := scores[name]
+		if !ok {
+			continue
+		}
+
+		if stats.DangerSystems == nil {
+			stats.DangerSystems = map[string]float64{}
+		}
+		stats.DangerSystems[name] = score
+		stats.Danger += score
+	}
+}
+
+// routeIntel returns the IDs of the applied reports for systems the route
+// passes through.
+func (a *App) routeIntel(route path.Route, applied []IntelStats) []string {
+	onRoute := map[string]bool{}
+	for _, sys := range route.Systems[1:] {
+		onRoute[a.universe.SystemName(sys)] = true
+	}
+
+	ids := []string{}
+	for _, r := range applied {
+		if onRoute[r.System] {
+			ids = append(ids, r.ID)
+		}
+	}
+
+	return ids
+}
+
+// wormholeEdges returns both directions of every open wormhole a ship of the
+// given size and mass fits through, labelled with the wormhole's ID.
+func (a *App) wormholeEdges(size wormhole.Size, mass int64) []path.Edge {
+	var edges []path.Edge
+	for _, wh := range a.wormholes.Live() {
+		if !wh.Allows(size, mass) {
+			continue
+		}
+
+		from, ok := a.universe.SystemID(wh.From)
+		if !ok {
+			continue
+		}
+
+		to, ok := a.universe.SystemID(wh.To)
+		if !ok {
+			continue
+		}
+
+		edges = append(edges,
+			path.Edge{From: from, To: to, Kind: path.Wormhole, Label: wh.ID},
+			path.Edge{From: to, To: from, Kind: path.Wormhole, Label: wh.ID},
+		)
+	}
+
+	return edges
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/pflag"
 )
@@ -12,6 +13,15 @@ func run() error {
 
 	pflag.StringVarP(&app.systemDataFile, "system-data", "s", "", "system data file (generated by graphmaker)")
 	pflag.StringVarP(&app.listen, "listen", "l", ":8080", "hostport to listen on")
+	pflag.StringToStringVar(&app.jumpRangeFlags, "jump-range", nil, "jump range overrides in light years per ship class, e.g. capital=7,black-ops=8")
+	pflag.StringVarP(&app.bridgeFile, "bridges", "b", "", "jump bridge file")
+	pflag.StringVarP(&app.wormholeFile, "wormholes", "w", "", "file to keep registered wormholes in across restarts")
+	pflag.StringVarP(&app.intelFile, "intel", "i", "", "file to keep hostile intel reports in across restarts")
+	pflag.StringVarP(&app.killFile, "kills", "k", "", "kill activity file (csv or json) for danger scores")
+	pflag.DurationVar(&app.killReload, "kills-reload", 5*time.Minute, "how often to reload the kill activity file")
+	pflag.DurationVar(&app.dangerModel.Window, "danger-window", 24*time.Hour, "ignore kills older than this")
+	pflag.DurationVar(&app.dangerModel.HalfLife, "danger-half-life", 2*time.Hour, "time for a kill's danger to halve")
+	pflag.StringVar(&app.profileFile, "profiles", "", "ship profile file")
 	pflag.Parse()
 
 	if err := app.Prep(); err != nil {
@@ -0,0 +1,81 @@
+package bitset
+
+import "math/bits"
+
+// Set is a fixed-size set of small non-negative integers.
+type Set []uint64
+
+func New(size int) Set {
+	return make(Set, (size+63)/64)
+}
+
+func (s Set) Add(i int) {
+	s[i/64] |= 1 << uint(i%64)
+}
+
+func (s Set) Remove(i int) {
+	s[i/64] &^= 1 << uint(i%64)
+}
+
+func (s Set) Has(i int) bool {
+	if i < 0 || i/64 >= len(s) {
+		return false
+	}
+
+	return s[i/64]&(1<<uint(i%64)) != 0
+}
+
+// Union adds every member of o to s.
+func (s Set) Union(o Set) {
+	for i := range s {
+		if i >= len(o) {
+			return
+		}
+
+		s[i] |= o[i]
+	}
+}
+
+func (s Set) Intersects(o Set) bool {
+	for i := range s {
+		if i >= len(o) {
+			return false
+		}
+
+		if s[i]&o[i] != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s Set) Count() int {
+	ct := 0
+	for _, w := range s {
+		ct += bits.OnesCount64(w)
+	}
+
+	return ct
+}
+
+func (s Set) Clone() Set {
+	c := make(Set, len(s))
+	copy(c, s)
+
+	return c
+}
+
+// Members lists the members of s in increasing order.
+func (s Set) Members() []int {
+	members := make([]int, 0, s.Count())
+	for i, w := range s {
+		for w != 0 {
+			b := bits.TrailingZeros64(w)
+			members = append(members, i*64+b)
+			w &^= 1 << uint(b)
+		}
+	}
+
+	return members
+}
@@ -0,0 +1,46 @@
+package bridge
+
+import (
+	"os"
+
+	"github.com/gsmcwhirter/go-util/v7/deferutil"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Bridge is a player-owned jump bridge (Ansiblex) between two systems. Bridges
+// work in both directions.
+type Bridge struct {
+	From  string `yaml:"from"`
+	To    string `yaml:"to"`
+	Owner string `yaml:"owner"`
+}
+
+type File struct {
+	Bridges []Bridge `yaml:"bridges"`
+}
+
+var ErrBadBridge = errors.New("bad bridge")
+
+func Load(path string) ([]Bridge, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open bridge file", "path", path)
+	}
+	defer deferutil.CheckDefer(f.Close)
+
+	contents := File{}
+
+	decoder := yaml.NewDecoder(f)
+	if err := decoder.Decode(&contents); err != nil {
+		return nil, errors.Wrap(err, "could not yaml decode bridge file", "path", path)
+	}
+
+	for i, b := range contents.Bridges {
+		if b.From == "" || b.To == "" {
+			return nil, errors.Wrap(ErrBadBridge, "bridge is missing a system", "path", path, "index", i)
+		}
+	}
+
+	return contents.Bridges, nil
+}
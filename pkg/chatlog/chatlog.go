@@ -0,0 +1,202 @@
+// Package chatlog reads the chat logs the EVE client writes and picks intel
+// reports out of them.
+package chatlog
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf16"
+)
+
+// Message is a single line of chat.
+type Message struct {
+	Time   time.Time
+	Sender string
+	Text   string
+}
+
+// lines look like "[ 2020.07.04 18:30:12 ] Some Pilot > Jita +3 red"
+var lineRe = regexp.MustCompile(`^\[ (\d{4}\.\d{2}\.\d{2} \d{2}:\d{2}:\d{2}) \] (.*?) > (.*)$`)
+
+const timeLayout = "2006.01.02 15:04:05"
+
+// ParseLine parses a chat line, reporting false for the log header and
+// anything else that is not a message. Log times are EVE time, which is UTC.
+func ParseLine(line string) (Message, bool) {
+	line = strings.TrimRight(strings.TrimPrefix(line, "\ufeff"), "\r\n")
+
+	m := lineRe.FindStringSubmatch(line)
+	if m == nil {
+		return Message{}, false
+	}
+
+	t, err := time.ParseInLocation(timeLayout, m[1], time.UTC)
+	if err != nil {
+		return Message{}, false
+	}
+
+	return Message{
+		Time:   t,
+		Sender: m[2],
+		Text:   m[3],
+	}, true
+}
+
+// decodeUTF16 decodes little-endian UTF-16 text, dropping any byte order mark.
+// It returns the number of bytes used, which stops short of an odd trailing
+// byte or a trailing high surrogate so the rest can be decoded with the next
+// read.
+func decodeUTF16(b []byte) (string, int) {
+	n := len(b) &^ 1
+	units := make([]uint16, 0, n/2)
+	for i := 0; i < n; i += 2 {
+		units = append(units, uint16(b[i])|uint16(b[i+1])<<8)
+	}
+
+	if len(units) > 0 && utf16.IsSurrogate(rune(units[len(units)-1])) && units[len(units)-1] < 0xdc00 {
+		units = units[:len(units)-1]
+		n -= 2
+	}
+
+	return strings.TrimPrefix(string(utf16.Decode(units)), "\ufeff"), n
+}
+
+// Index recognises system names in free text. Names are matched regardless of
+// case and spacing, so "sarum prime" finds SarumPrime, and may span up to
+// three words.
+type Index struct {
+	names map[string]int
+}
+
+const maxNameWords = 3
+
+func normalize(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), ""))
+}
+
+func NewIndex(names map[string]int) *Index {
+	ix := &Index{
+		names: make(map[string]int, len(names)),
+	}
+
+	for name, id := range names {
+		ix.names[normalize(name)] = id
+	}
+
+	return ix
+}
+
+func (ix *Index) Lookup(name string) (int, bool) {
+	id, ok := ix.names[normalize(name)]
+	return id, ok
+}
+
+func words(text string) []string {
+	fields := strings.Fields(text)
+	for i, f := range fields {
+		fields[i] = strings.TrimFunc(f, func(r rune) bool {
+			return r != '-' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+	}
+
+	return fields
+}
+
+// Systems returns the systems named in text, in the order they appear. Longer
+// names win, so a two-word name is not also reported as its first word.
+func (ix *Index) Systems(text string) []int {
+	var systems []int
+	seen := map[int]bool{}
+
+	w := words(text)
+	for i := 0; i < len(w); {
+		matched := 0
+		for n := maxNameWords; n > 0; n-- {
+			if i+n > len(w) {
+				continue
+			}
+
+			id, ok := ix.names[normalize(strings.Join(w[i:i+n], ""))]
+			if !ok {
+				continue
+			}
+
+			if !seen[id] {
+				seen[id] = true
+				systems = append(systems, id)
+			}
+			matched = n
+			break
+		}
+
+		if matched == 0 {
+			matched = 1
+		}
+		i += matched
+	}
+
+	return systems
+}
+
+var clearWords = map[string]bool{
+	"clear":   true,
+	"clr":     true,
+	"cleared": true,
+}
+
+// Event is an intel report picked out of a chat log. Clear is set when the
+// report says the systems are clear rather than hostile.
+type Event struct {
+	Time    time.Time
+	Channel string
+	Sender  string
+	Text    string
+	Systems []int
+	Clear   bool
+}
+
+const systemSender = "EVE System"
+
+// Intel turns a message into an intel event, reporting false if it names no
+// systems or comes from the client rather than a pilot.
+func (ix *Index) Intel(channel string, m Message) (Event, bool) {
+	if m.Sender == systemSender {
+		return Event{}, false
+	}
+
+	systems := ix.Systems(m.Text)
+	if len(systems) == 0 {
+		return Event{}, false
+	}
+
+	e := Event{
+		Time:    m.Time,
+		Channel: channel,
+		Sender:  m.Sender,
+		Text:    m.Text,
+		Systems: systems,
+	}
+
+	for _, w := range words(m.Text) {
+		if clearWords[strings.ToLower(w)] {
+			e.Clear = true
+			break
+		}
+	}
+
+	return e, true
+}
+
+const localPrefix = "Channel changed to Local : "
+
+// Moved reports the system a Local channel message says the pilot has just
+// arrived in.
+func (ix *Index) Moved(m Message) (int, bool) {
+	if m.Sender != systemSender || !strings.HasPrefix(m.Text, localPrefix) {
+		return 0, false
+	}
+
+	return ix.Lookup(strings.TrimPrefix(m.Text, localPrefix))
+}
@@ -0,0 +1,196 @@
+package chatlog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// utf16LE encodes s the way the EVE client writes its logs.
+func utf16LE(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	b := make([]byte, 0, 2*len(units))
+	for _, u := range units {
+		b = append(b, byte(u), byte(u>>8))
+	}
+
+	return b
+}
+
+func TestDecodeUTF16(t *testing.T) {
+	smile := utf16LE("\U0001F600") // a surrogate pair
+
+	tests := []struct {
+		name     string
+		data     []byte
+		want     string
+		wantUsed int
+	}{
+		{
+			name:     "plain",
+			data:     utf16LE("Jita"),
+			want:     "Jita",
+			wantUsed: 8,
+		},
+		{
+			name:     "byte order mark",
+			data:     utf16LE("\ufeffJita"),
+			want:     "Jita",
+			wantUsed: 10,
+		},
+		{
+			name:     "odd trailing byte",
+			data:     append(utf16LE("Ji"), 't'),
+			want:     "Ji",
+			wantUsed: 4,
+		},
+		{
+			name:     "split surrogate",
+			data:     append(utf16LE("a"), smile[:2]...),
+			want:     "a",
+			wantUsed: 2,
+		},
+		{
+			name:     "split surrogate and odd byte",
+			data:     append(utf16LE("a"), smile[:3]...),
+			want:     "a",
+			wantUsed: 2,
+		},
+		{
+			name:     "whole surrogate pair",
+			data:     append(utf16LE("a"), smile...),
+			want:     "a\U0001F600",
+			wantUsed: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, used := decodeUTF16(tt.data)
+			if got != tt.want || used != tt.wantUsed {
+				t.Errorf("got %q, %d; want %q, %d", got, used, tt.want, tt.wantUsed)
+			}
+		})
+	}
+}
+
+func TestIndexSystems(t *testing.T) {
+	ix := NewIndex(map[string]int{
+		"Jita":         1,
+		"Sarum":        2,
+		"SarumPrime":   3,
+		"Old Man Star": 4,
+		"1DQ1-A":       5,
+	})
+
+	tests := []struct {
+		name string
+		text string
+		want []int
+	}{
+		{name: "one word", text: "Jita +3 red", want: []int{1}},
+		{name: "two words win", text: "sarum prime +2", want: []int{3}},
+		{name: "prefix alone", text: "sarum nv", want: []int{2}},
+		{name: "three words", text: "old man star gate camp", want: []int{4}},
+		{name: "punctuation and repeats", text: "1DQ1-A, jita. JITA!", want: []int{5, 1}},
+		{name: "in order", text: "Jita to SarumPrime", want: []int{1, 3}},
+		{name: "nothing", text: "all quiet"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ix.Systems(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// logHeader is the start of a log as the client writes it.
+const logHeader = "\ufeff\r\n\r\n" +
+	"        ---------------------------------------------------------------\r\n\r\n" +
+	"          Channel ID:      -12345\r\n" +
+	"          Channel Name:    Corp Intel\r\n" +
+	"          Listener:        Some Pilot\r\n" +
+	"          Session started: 2020.07.04 18:30:12\r\n" +
+	"        ---------------------------------------------------------------\r\n\r\n"
+
+type logged struct {
+	channel string
+	m       Message
+}
+
+func TestTailer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chatlog")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "Corp Intel_20200704_183012_90000001.txt")
+	if err := ioutil.WriteFile(path, utf16LE(logHeader+"[ 2020.07.04 18:30:20 ] Some Pilot > Jita +3 red\r\n"), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	appendLog := func(b []byte) {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer f.Close()
+
+		if _, err := f.Write(b); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	msg := func(hms, sender, text string) logged {
+		ts, _ := time.Parse("2006.01.02 15:04:05", "2020.07.04 "+hms)
+		return logged{channel: "Corp Intel", m: Message{Time: ts, Sender: sender, Text: text}}
+	}
+
+	tailer := NewTailer(dir, []string{"corp intel"}, true)
+
+	var got []logged
+	poll := func() []logged {
+		got = nil
+		if err := tailer.Poll(func(channel string, m Message) { got = append(got, logged{channel, m}) }); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return got
+	}
+
+	// the whole log is read, header and all, with its byte order mark
+	if got, want := poll(), []logged{msg("18:30:20", "Some Pilot", "Jita +3 red")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("first poll: got %v; want %v", got, want)
+	}
+
+	// a line written in pieces, split inside a character, is held back until
+	// it is complete
+	line := utf16LE("[ 2020.07.04 18:31:00 ] Other Pilot > Amarr clr\r\n")
+	appendLog(line[:21])
+	if got := poll(); got != nil {
+		t.Errorf("partial poll: got %v; want nothing", got)
+	}
+
+	appendLog(line[21:])
+	if got, want := poll(), []logged{msg("18:31:00", "Other Pilot", "Amarr clr")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resumed poll: got %v; want %v", got, want)
+	}
+
+	if got := poll(); got != nil {
+		t.Errorf("idle poll: got %v; want nothing", got)
+	}
+
+	// a log that shrinks has been replaced, and is read again from the start
+	if err := ioutil.WriteFile(path, utf16LE("\ufeff[ 2020.07.04 19:00:00 ] Some Pilot > Hek\r\n"), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := poll(), []logged{msg("19:00:00", "Some Pilot", "Hek")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("truncated poll: got %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,152 @@
+package chatlog
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gsmcwhirter/go-util/v7/deferutil"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+)
+
+// log files are named like "Corp Intel_20200704_183012_90000001.txt"
+var fileRe = regexp.MustCompile(`^(.*)_\d{8}_\d{6}(?:_\d+)?\.txt$`)
+
+type logFile struct {
+	channel string
+	offset  int64
+	partial string
+}
+
+// Tailer follows the chat logs in a directory, passing on each new message in
+// the channels it watches. Logs that exist when it starts are read from their
+// end unless fromStart is set; logs created later are read in full.
+type Tailer struct {
+	dir       string
+	channels  map[string]bool
+	fromStart bool
+
+	files  map[string]*logFile
+	polled bool
+}
+
+// NewTailer watches the given channels, or every channel if there are none.
+func NewTailer(dir string, channels []string, fromStart bool) *Tailer {
+	t := &Tailer{
+		dir:       dir,
+		fromStart: fromStart,
+		files:     map[string]*logFile{},
+	}
+
+	if len(channels) > 0 {
+		t.channels = map[string]bool{}
+		for _, c := range channels {
+			t.channels[strings.ToLower(c)] = true
+		}
+	}
+
+	return t
+}
+
+// Poll reads whatever has been added to the logs since the last poll and
+// calls fn with each new message, in order within each log.
+func (t *Tailer) Poll(fn func(channel string, m Message)) error {
+	infos, err := ioutil.ReadDir(t.dir)
+	if err != nil {
+		return errors.Wrap(err, "could not list chat logs", "dir", t.dir)
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ModTime().Before(infos[j].ModTime()) })
+
+	for _, info := range infos {
+		if info.IsDir() {
+			continue
+		}
+
+		lf, ok := t.files[info.Name()]
+		if !ok {
+			m := fileRe.FindStringSubmatch(info.Name())
+			if m == nil || (t.channels != nil && !t.channels[strings.ToLower(m[1])]) {
+				continue
+			}
+
+			lf = &logFile{channel: m[1]}
+			if !t.polled && !t.fromStart {
+				lf.offset = info.Size()
+			}
+			t.files[info.Name()] = lf
+		}
+
+		if info.Size() < lf.offset { // truncated or replaced
+			lf.offset = 0
+			lf.partial = ""
+		}
+
+		if info.Size() == lf.offset {
+			continue
+		}
+
+		if err := t.read(filepath.Join(t.dir, info.Name()), lf, fn); err != nil {
+			return err
+		}
+	}
+
+	t.polled = true
+
+	return nil
+}
+
+func (t *Tailer) read(path string, lf *logFile, fn func(channel string, m Message)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "could not open chat log", "path", path)
+	}
+	defer deferutil.CheckDefer(f.Close)
+
+	if _, err := f.Seek(lf.offset, io.SeekStart); err != nil {
+		return errors.Wrap(err, "could not seek in chat log", "path", path)
+	}
+
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return errors.Wrap(err, "could not read chat log", "path", path)
+	}
+
+	text, used := decodeUTF16(data)
+	lf.offset += int64(used)
+
+	lines := strings.Split(lf.partial+text, "\n")
+	lf.partial = lines[len(lines)-1]
+
+	for _, line := range lines[:len(lines)-1] {
+		if m, ok := ParseLine(line); ok {
+			fn(lf.channel, m)
+		}
+	}
+
+	return nil
+}
+
+// Tail polls the logs every interval until the context is done.
+func (t *Tailer) Tail(ctx context.Context, interval time.Duration, fn func(channel string, m Message)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := t.Poll(fn); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
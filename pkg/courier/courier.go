@@ -0,0 +1,121 @@
+// Package courier reads lists of courier contracts to plan hauling routes for.
+package courier
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gsmcwhirter/go-util/v7/deferutil"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+)
+
+// Contract is a courier contract: cargo to collect in one system and deliver
+// to another. ID is whatever the hauler uses to tell contracts apart.
+type Contract struct {
+	ID      string `json:"id"`
+	Pickup  string `json:"pickup"`
+	Dropoff string `json:"dropoff"`
+}
+
+var ErrBadContract = errors.New("bad contract data")
+
+// Load reads contracts from a CSV or JSON file, chosen by the file's extension.
+func Load(path string) ([]Contract, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open contract file", "path", path)
+	}
+	defer deferutil.CheckDefer(f.Close)
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ReadCSV(f)
+	case ".json":
+		return ReadJSON(f)
+	default:
+		return nil, errors.Wrap(ErrBadContract, "contract file must be .csv or .json", "path", path)
+	}
+}
+
+// ReadCSV reads contracts from CSV with a header row naming the pickup and
+// dropoff columns, and optionally an id column. Contracts without an id are
+// numbered from 1.
+func ReadCSV(r io.Reader) ([]Contract, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read contract header")
+	}
+
+	cols := map[string]int{"id": -1, "pickup": -1, "dropoff": -1}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := cols[name]; ok {
+			cols[name] = i
+		}
+	}
+
+	for name, i := range cols {
+		if i == -1 && name != "id" {
+			return nil, errors.Wrap(ErrBadContract, "contracts are missing a column", "column", name)
+		}
+	}
+
+	var contracts []Contract
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read contracts", "line", line)
+		}
+
+		c := Contract{
+			Pickup:  strings.TrimSpace(record[cols["pickup"]]),
+			Dropoff: strings.TrimSpace(record[cols["dropoff"]]),
+		}
+		if i := cols["id"]; i >= 0 {
+			c.ID = strings.TrimSpace(record[i])
+		}
+
+		contracts = append(contracts, c)
+	}
+
+	return contracts, Validate(contracts)
+}
+
+// ReadJSON reads contracts from a JSON list of objects with pickup, dropoff,
+// and optionally id keys.
+func ReadJSON(r io.Reader) ([]Contract, error) {
+	var contracts []Contract
+	if err := json.NewDecoder(r).Decode(&contracts); err != nil {
+		return nil, errors.Wrap(err, "could not json decode contracts")
+	}
+
+	return contracts, Validate(contracts)
+}
+
+// Validate checks every contract has a pickup and dropoff, and numbers any
+// contracts without an ID.
+func Validate(contracts []Contract) error {
+	for i := range contracts {
+		c := &contracts[i]
+		if c.Pickup == "" || c.Dropoff == "" {
+			return errors.Wrap(ErrBadContract, "contract needs a pickup and a dropoff", "index", i)
+		}
+
+		if c.ID == "" {
+			c.ID = strconv.Itoa(i + 1)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,299 @@
+// Package danger turns kill activity into per-system danger scores.
+package danger
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gsmcwhirter/go-util/v7/deferutil"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+)
+
+// Kill is a single ship loss, as exported from a killboard.
+type Kill struct {
+	System   string    `json:"system"`
+	Time     time.Time `json:"timestamp"`
+	ShipType string    `json:"ship_type"`
+}
+
+var ErrBadKillData = errors.New("bad kill data")
+
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006.01.02 15:04:05",
+	"2006-01-02T15:04:05",
+}
+
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, errors.Wrap(ErrBadKillData, "unknown timestamp format", "timestamp", s)
+}
+
+// Load reads kills from a CSV or JSON file, chosen by the file's extension.
+// CSV files need a header row naming the system, timestamp, and ship_type
+// columns; JSON files hold a list of objects with those keys.
+func Load(path string) ([]Kill, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open kill file", "path", path)
+	}
+	defer deferutil.CheckDefer(f.Close)
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return readCSV(f)
+	case ".json":
+		return readJSON(f)
+	default:
+		return nil, errors.Wrap(ErrBadKillData, "kill file must be .csv or .json", "path", path)
+	}
+}
+
+func readCSV(r io.Reader) ([]Kill, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read kill file header")
+	}
+
+	cols := map[string]int{"system": -1, "timestamp": -1, "ship_type": -1}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := cols[name]; ok {
+			cols[name] = i
+		}
+	}
+
+	for name, i := range cols {
+		if i == -1 && name != "ship_type" {
+			return nil, errors.Wrap(ErrBadKillData, "kill file is missing a column", "column", name)
+		}
+	}
+
+	var kills []Kill
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read kill file", "line", line)
+		}
+
+		t, err := parseTime(record[cols["timestamp"]])
+		if err != nil {
+			return nil, errors.Wrap(err, "bad kill", "line", line)
+		}
+
+		k := Kill{
+			System: record[cols["system"]],
+			Time:   t,
+		}
+		if i := cols["ship_type"]; i >= 0 {
+			k.ShipType = record[i]
+		}
+
+		kills = append(kills, k)
+	}
+
+	return kills, nil
+}
+
+func readJSON(r io.Reader) ([]Kill, error) {
+	var raw []struct {
+		System    string `json:"system"`
+		Timestamp string `json:"timestamp"`
+		ShipType  string `json:"ship_type"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, errors.Wrap(err, "could not json decode kill file")
+	}
+
+	kills := make([]Kill, len(raw))
+	for i, k := range raw {
+		t, err := parseTime(k.Timestamp)
+		if err != nil {
+			return nil, errors.Wrap(err, "bad kill", "index", i)
+		}
+
+		kills[i] = Kill{
+			System:   k.System,
+			Time:     t,
+			ShipType: k.ShipType,
+		}
+	}
+
+	return kills, nil
+}
+
+// Model says how kills turn into danger. Kills older than Window are ignored,
+// and each kill counts half as much for every HalfLife that has passed since.
+type Model struct {
+	Window   time.Duration
+	HalfLife time.Duration
+}
+
+// Scores holds the danger score and recent kill count of each system with
+// kills in the window, keyed by system name.
+type Scores struct {
+	Computed time.Time
+	Danger   map[string]float64
+	Kills    map[string]int
+}
+
+func (m Model) Score(kills []Kill, now time.Time) Scores {
+	s := Scores{
+		Computed: now,
+		Danger:   map[string]float64{},
+		Kills:    map[string]int{},
+	}
+
+	for _, k := range kills {
+		age := now.Sub(k.Time)
+		if age < 0 {
+			age = 0
+		}
+
+		if m.Window > 0 && age > m.Window {
+			continue
+		}
+
+		weight := 1.0
+		if m.HalfLife > 0 {
+			weight = math.Exp2(-float64(age) / float64(m.HalfLife))
+		}
+
+		s.Danger[k.System] += weight
+		s.Kills[k.System]++
+	}
+
+	return s
+}
+
+// Constraints turns the scores into route constraints, keyed by system name:
+// systems scoring over maxDanger are avoided, if it is positive, and jumps
+// into the rest cost weight times their score, rounded. Avoids are sorted.
+func (s Scores) Constraints(weight, maxDanger float64) ([]string, map[string]int) {
+	var avoids []string
+	penalties := map[string]int{}
+
+	for name, score := range s.Danger {
+		if maxDanger > 0 && score > maxDanger {
+			avoids = append(avoids, name)
+			continue
+		}
+
+		if p := int(math.Round(weight * score)); p > 0 {
+			penalties[name] = p
+		}
+	}
+
+	sort.Strings(avoids)
+
+	return avoids, penalties
+}
+
+// Watcher keeps the scores from a kill file up to date, reloading the file on
+// a schedule so exports dropped in place are picked up.
+type Watcher struct {
+	path  string
+	model Model
+	now   func() time.Time
+
+	mu     sync.RWMutex
+	kills  []Kill
+	scores Scores
+}
+
+// NewWatcher loads the kill file once, failing if it cannot be read.
+func NewWatcher(path string, model Model) (*Watcher, error) {
+	w := &Watcher{
+		path:  path,
+		model: model,
+		now:   time.Now,
+	}
+
+	if err := w.Reload(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *Watcher) Reload() error {
+	kills, err := Load(w.path)
+	if err != nil {
+		return err
+	}
+
+	scores := w.model.Score(kills, w.now())
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.kills = kills
+	w.scores = scores
+
+	return nil
+}
+
+// Run reloads the kill file every interval until the context is done. A
+// failed reload keeps the previous data and is passed to onError.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := w.Reload(); err != nil {
+			onError(err)
+		}
+	}
+}
+
+// rescoreAfter is how stale scores may get before they are decayed again.
+const rescoreAfter = time.Minute
+
+func (w *Watcher) Scores() Scores {
+	now := w.now()
+
+	w.mu.RLock()
+	scores := w.scores
+	w.mu.RUnlock()
+
+	if now.Sub(scores.Computed) < rescoreAfter {
+		return scores
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if now.Sub(w.scores.Computed) >= rescoreAfter {
+		w.scores = w.model.Score(w.kills, now)
+	}
+
+	return w.scores
+}
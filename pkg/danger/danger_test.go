@@ -0,0 +1,233 @@
+package danger
+
+import (
+	stderr "errors"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func TestScore(t *testing.T) {
+	m := Model{Window: 6 * time.Hour, HalfLife: 2 * time.Hour}
+
+	tests := []struct {
+		name      string
+		kills     []Kill
+		wantScore float64
+		wantKills int
+	}{
+		{
+			name:      "just now",
+			kills:     []Kill{{System: "Tama", Time: testNow}},
+			wantScore: 1,
+			wantKills: 1,
+		},
+		{
+			name:      "one half life",
+			kills:     []Kill{{System: "Tama", Time: testNow.Add(-2 * time.Hour)}},
+			wantScore: 0.5,
+			wantKills: 1,
+		},
+		{
+			name: "several ages",
+			kills: []Kill{
+				{System: "Tama", Time: testNow.Add(-4 * time.Hour)},
+				{System: "Tama", Time: testNow.Add(-6 * time.Hour)},
+			},
+			wantScore: 0.375,
+			wantKills: 2,
+		},
+		{
+			name:  "outside the window",
+			kills: []Kill{{System: "Tama", Time: testNow.Add(-7 * time.Hour)}},
+		},
+		{
+			name:      "in the future",
+			kills:     []Kill{{System: "Tama", Time: testNow.Add(time.Hour)}},
+			wantScore: 1,
+			wantKills: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := m.Score(tt.kills, testNow)
+
+			if got := s.Danger["Tama"]; math.Abs(got-tt.wantScore) > 1e-9 {
+				t.Errorf("got score %v; want %v", got, tt.wantScore)
+			}
+
+			if got := s.Kills["Tama"]; got != tt.wantKills {
+				t.Errorf("got %d kills; want %d", got, tt.wantKills)
+			}
+		})
+	}
+}
+
+// TestWatcherScores checks cached scores are decayed again once they are
+// stale, without reloading the file.
+func TestWatcherScores(t *testing.T) {
+	now := testNow
+	w := &Watcher{
+		model: Model{HalfLife: time.Hour},
+		now:   func() time.Time { return now },
+		kills: []Kill{{System: "Tama", Time: testNow}},
+	}
+	w.scores = w.model.Score(w.kills, now)
+
+	now = now.Add(30 * time.Second)
+	if got := w.Scores().Danger["Tama"]; got != 1 {
+		t.Errorf("got %v before rescoring; want 1", got)
+	}
+
+	now = testNow.Add(time.Hour)
+	if got := w.Scores().Danger["Tama"]; math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("got %v after an hour; want 0.5", got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "danger")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name    string
+		file    string
+		data    string
+		want    []Kill
+		wantErr error
+	}{
+		{
+			name: "csv",
+			file: "kills.csv",
+			data: "timestamp, system\n2020-06-01 11:00:00, Tama\n2020.06.01 10:00:00, Amamake\n",
+			want: []Kill{
+				{System: "Tama", Time: testNow.Add(-time.Hour)},
+				{System: "Amamake", Time: testNow.Add(-2 * time.Hour)},
+			},
+		},
+		{
+			name: "json",
+			file: "kills.json",
+			data: `[{"system": "Tama", "timestamp": "2020-06-01T11:00:00Z", "ship_type": "Rifter"}]`,
+			want: []Kill{{System: "Tama", Time: testNow.Add(-time.Hour), ShipType: "Rifter"}},
+		},
+		{
+			name:    "missing column",
+			file:    "kills.csv",
+			data:    "system,ship_type\nTama,Rifter\n",
+			wantErr: ErrBadKillData,
+		},
+		{
+			name:    "bad timestamp",
+			file:    "kills.csv",
+			data:    "system,timestamp\nTama,2020-06-01 11:00:00\nAmamake,yesterday\n",
+			wantErr: ErrBadKillData,
+		},
+		{
+			name: "partial csv row",
+			file: "kills.csv",
+			data: "system,timestamp\nTama,2020-06-01 11:00:00\nAmamake\n",
+		},
+		{
+			name: "partial json",
+			file: "kills.json",
+			data: `[{"system": "Tama", "timestamp": "2020-06-01T11:00:00Z"}, {"sys`,
+		},
+		{
+			name:    "unknown extension",
+			file:    "kills.txt",
+			data:    "Tama",
+			wantErr: ErrBadKillData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			if err := ioutil.WriteFile(path, []byte(tt.data), 0644); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got, err := Load(path)
+			if tt.want == nil {
+				if err == nil {
+					t.Fatalf("got %v; want an error", got)
+				}
+				if tt.wantErr != nil && !stderr.Is(err, tt.wantErr) {
+					t.Errorf("got %v; want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConstraints(t *testing.T) {
+	s := Scores{Danger: map[string]float64{"Tama": 4.2, "Amamake": 1.5, "Rancer": 0.2}}
+
+	tests := []struct {
+		name          string
+		weight        float64
+		maxDanger     float64
+		wantAvoids    []string
+		wantPenalties map[string]int
+	}{
+		{
+			name:          "weight only",
+			weight:        2,
+			wantPenalties: map[string]int{"Tama": 8, "Amamake": 3},
+		},
+		{
+			name:          "cutoff only",
+			maxDanger:     1,
+			wantAvoids:    []string{"Amamake", "Tama"},
+			wantPenalties: map[string]int{},
+		},
+		{
+			name:          "both",
+			weight:        3,
+			maxDanger:     2,
+			wantAvoids:    []string{"Tama"},
+			wantPenalties: map[string]int{"Amamake": 5, "Rancer": 1},
+		},
+		{
+			name:          "score at the cutoff",
+			weight:        1,
+			maxDanger:     1.5,
+			wantAvoids:    []string{"Tama"},
+			wantPenalties: map[string]int{"Amamake": 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			avoids, penalties := s.Constraints(tt.weight, tt.maxDanger)
+
+			if !reflect.DeepEqual(avoids, tt.wantAvoids) {
+				t.Errorf("got avoids %v; want %v", avoids, tt.wantAvoids)
+			}
+
+			if !reflect.DeepEqual(penalties, tt.wantPenalties) {
+				t.Errorf("got penalties %v; want %v", penalties, tt.wantPenalties)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Package filestore keeps small YAML files that the server rewrites as their
+// contents change.
+package filestore
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/gsmcwhirter/go-util/v7/deferutil"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// NewID returns a random 16 character hex ID.
+func NewID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "could not generate id")
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// Load decodes the file at path into v. A missing or empty file leaves v as
+// it is.
+func Load(path string, v interface{}) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not open file", "path", path)
+	}
+	defer deferutil.CheckDefer(f.Close)
+
+	if err := yaml.NewDecoder(f).Decode(v); err != nil && err != io.EOF {
+		return errors.Wrap(err, "could not yaml decode file", "path", path)
+	}
+
+	return nil
+}
+
+// Save encodes v and replaces the file at path with it. The data goes to a
+// temporary file in the same directory first, so a crash never leaves a
+// partial file behind.
+func Save(path string, v interface{}) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "could not yaml encode file", "path", path)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return errors.Wrap(err, "could not create file", "path", path)
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return errors.Wrap(err, "could not write file", "path", path)
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return errors.Wrap(err, "could not write file", "path", path)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return errors.Wrap(err, "could not replace file", "path", path)
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package filestore
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gsmcwhirter/go-util/v7/errors"
+)
+
+// Record is an entry in a Store, kept until it expires.
+type Record interface {
+	RecordID() string
+	Expiry() time.Time
+}
+
+// File is the contents of a Store's file, converting between its own list of
+// entries and the records a Store holds.
+type File interface {
+	Records() []Record
+	SetRecords(records []Record)
+}
+
+var ErrMissingID = errors.New("record is missing an id")
+
+// Store holds records by ID. Expired records are left out of Live and dropped
+// on the next change. If it has a path, every change is written back to that
+// file.
+type Store struct {
+	path string
+	file File
+	now  func() time.Time
+
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+// NewStore creates a store backed by the file at path, loading any records
+// already in it through file. An empty path keeps the store in memory only.
+func NewStore(path string, file File) (*Store, error) {
+	s := &Store{
+		path:    path,
+		file:    file,
+		now:     time.Now,
+		records: map[string]Record{},
+	}
+
+	if path == "" {
+		return s, nil
+	}
+
+	if err := Load(path, file); err != nil {
+		return nil, err
+	}
+
+	for _, r := range file.Records() {
+		s.records[r.RecordID()] = r
+	}
+
+	return s, nil
+}
+
+// Now is the store's current time, which decides what has expired.
+func (s *Store) Now() time.Time {
+	return s.now()
+}
+
+// Put adds or replaces a record.
+func (s *Store) Put(r Record) error {
+	if r.RecordID() == "" {
+		return ErrMissingID
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[r.RecordID()] = r
+
+	return s.save()
+}
+
+// Remove drops a record. It reports whether the record was known.
+func (s *Store) Remove(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.records[id]; !ok {
+		return false, nil
+	}
+
+	delete(s.records, id)
+
+	return true, s.save()
+}
+
+func (s *Store) Get(id string) (Record, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.records[id]
+
+	return r, ok
+}
+
+// Live returns the records that have not yet expired, in no particular order.
+func (s *Store) Live() []Record {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	live := make([]Record, 0, len(s.records))
+	for _, r := range s.records {
+		if r.Expiry().After(now) {
+			live = append(live, r)
+		}
+	}
+
+	return live
+}
+
+// save drops expired records and writes the rest to the file, ordered by ID.
+// The caller must hold the lock.
+func (s *Store) save() error {
+	now := s.now()
+	for id, r := range s.records {
+		if !r.Expiry().After(now) {
+			delete(s.records, id)
+		}
+	}
+
+	if s.path == "" {
+		return nil
+	}
+
+	records := make([]Record, 0, len(s.records))
+	for _, r := range s.records {
+		records = append(records, r)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].RecordID() < records[j].RecordID() })
+
+	s.file.SetRecords(records)
+
+	return Save(s.path, s.file)
+}
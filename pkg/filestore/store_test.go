@@ -0,0 +1,98 @@
+package filestore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testRecord struct {
+	ID      string    `yaml:"id"`
+	Expires time.Time `yaml:"expires"`
+}
+
+func (r testRecord) RecordID() string  { return r.ID }
+func (r testRecord) Expiry() time.Time { return r.Expires }
+
+type testFile struct {
+	Entries []testRecord `yaml:"entries"`
+}
+
+func (f *testFile) Records() []Record {
+	records := make([]Record, len(f.Entries))
+	for i, r := range f.Entries {
+		records[i] = r
+	}
+	return records
+}
+
+func (f *testFile) SetRecords(records []Record) {
+	f.Entries = make([]testRecord, len(records))
+	for i, r := range records {
+		f.Entries[i] = r.(testRecord)
+	}
+}
+
+// TestStore checks records survive a reload, expired ones are hidden from Live
+// at once, and they are dropped from the file on the next change.
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filestore")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "store.yml")
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	s, err := NewStore(path, &testFile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.now = func() time.Time { return now }
+
+	for _, r := range []testRecord{
+		{ID: "a", Expires: now.Add(time.Hour)},
+		{ID: "b", Expires: now.Add(2 * time.Hour)},
+	} {
+		if err := s.Put(r); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if err := s.Put(testRecord{Expires: now.Add(time.Hour)}); err != ErrMissingID {
+		t.Errorf("got %v; want ErrMissingID", err)
+	}
+
+	now = now.Add(90 * time.Minute)
+	if live := s.Live(); len(live) != 1 || live[0].RecordID() != "b" {
+		t.Errorf("got %v; want only b", live)
+	}
+
+	if _, ok := s.Get("a"); !ok {
+		t.Error("expired record dropped before the next change")
+	}
+
+	if ok, err := s.Remove("missing"); ok || err != nil {
+		t.Errorf("got %v, %v; want false, nil", ok, err)
+	}
+
+	if err := s.Put(testRecord{ID: "c", Expires: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	file := &testFile{}
+	if _, err := NewStore(path, file); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var ids []string
+	for _, r := range file.Entries {
+		ids = append(ids, r.ID)
+	}
+	if got, want := ids, []string{"b", "c"}; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
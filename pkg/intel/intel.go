@@ -0,0 +1,154 @@
+package intel
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/filestore"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+)
+
+// Report is a sighting of hostiles in a system. It counts for less and less as
+// it ages, until it expires.
+type Report struct {
+	ID       string    `yaml:"id"`
+	System   string    `yaml:"system"`
+	Reported time.Time `yaml:"reported"`
+	Expires  time.Time `yaml:"expires"`
+	Count    int       `yaml:"count,omitempty"` // number of hostiles, if known
+	Reporter string    `yaml:"reporter,omitempty"`
+	Note     string    `yaml:"note,omitempty"`
+}
+
+// Weight is how much the report still counts at the given time, falling
+// linearly from 1 when reported to 0 when it expires.
+func (r Report) Weight(now time.Time) float64 {
+	ttl := r.Expires.Sub(r.Reported)
+	if ttl <= 0 || !now.Before(r.Expires) {
+		return 0
+	}
+
+	left := r.Expires.Sub(now)
+	if left > ttl {
+		return 1
+	}
+
+	return float64(left) / float64(ttl)
+}
+
+// Penalty scales max by the report's weight, rounding up so a live report
+// always costs something.
+func (r Report) Penalty(now time.Time, max int) int {
+	return int(math.Ceil(float64(max) * r.Weight(now)))
+}
+
+// RecordID and Expiry let reports be kept in a filestore.Store.
+func (r Report) RecordID() string  { return r.ID }
+func (r Report) Expiry() time.Time { return r.Expires }
+
+type File struct {
+	Reports []Report `yaml:"reports"`
+}
+
+func (f *File) Records() []filestore.Record {
+	records := make([]filestore.Record, len(f.Reports))
+	for i, r := range f.Reports {
+		records[i] = r
+	}
+
+	return records
+}
+
+func (f *File) SetRecords(records []filestore.Record) {
+	f.Reports = make([]Report, len(records))
+	for i, r := range records {
+		f.Reports[i] = r.(Report)
+	}
+}
+
+var ErrBadReport = errors.New("bad intel report")
+
+// Store holds the intel reports. Expired reports are left out of Live and
+// dropped on the next change. If it has a path, every change is written back
+// to that file.
+type Store struct {
+	reports *filestore.Store
+}
+
+// NewStore creates a store backed by the file at path, loading any reports
+// already in it. An empty path keeps the store in memory only.
+func NewStore(path string) (*Store, error) {
+	reports, err := filestore.NewStore(path, &File{})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load intel file")
+	}
+
+	return &Store{reports: reports}, nil
+}
+
+// Add records a report that lasts for ttl, giving it an ID and timestamp if
+// it has none, and returns it as stored.
+func (s *Store) Add(r Report, ttl time.Duration) (Report, error) {
+	if r.System == "" {
+		return r, errors.Wrap(ErrBadReport, "report is missing a system")
+	}
+
+	if ttl <= 0 {
+		return r, errors.Wrap(ErrBadReport, "report ttl must be positive")
+	}
+
+	now := s.reports.Now()
+	if r.Reported.IsZero() {
+		r.Reported = now
+	}
+	r.Expires = r.Reported.Add(ttl)
+
+	if !r.Expires.After(now) {
+		return r, errors.Wrap(ErrBadReport, "report has already expired")
+	}
+
+	if r.ID == "" {
+		id, err := filestore.NewID()
+		if err != nil {
+			return r, err
+		}
+		r.ID = id
+	}
+
+	if err := s.reports.Put(r); err != nil {
+		return r, errors.Wrap(err, "could not save intel reports")
+	}
+
+	return r, nil
+}
+
+// Remove drops a report, e.g. once the system is clear. It reports whether the
+// report was known.
+func (s *Store) Remove(id string) (bool, error) {
+	ok, err := s.reports.Remove(id)
+	if err != nil {
+		return ok, errors.Wrap(err, "could not save intel reports")
+	}
+
+	return ok, nil
+}
+
+// Live returns the reports that have not yet expired, newest first.
+func (s *Store) Live() []Report {
+	records := s.reports.Live()
+
+	live := make([]Report, len(records))
+	for i, r := range records {
+		live[i] = r.(Report)
+	}
+
+	sort.Slice(live, func(i, j int) bool {
+		if !live[i].Reported.Equal(live[j].Reported) {
+			return live[i].Reported.After(live[j].Reported)
+		}
+		return live[i].ID < live[j].ID
+	})
+
+	return live
+}
@@ -0,0 +1,148 @@
+package jump
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// minFatigue is the fatigue a jump starts from when the pilot has less.
+	minFatigue = 10 * time.Minute
+	// maxFatigue and maxReactivation are the caps on jump fatigue and on the
+	// jump drive reactivation timer.
+	maxFatigue      = 5 * time.Hour
+	maxReactivation = 30 * time.Minute
+)
+
+// Hull describes how a class of jump-capable ship uses fuel and accumulates
+// fatigue. FuelPerLY is isotopes per light year before skills, and
+// FatigueReduction is the fraction of each jump's distance ignored for fatigue.
+type Hull struct {
+	FuelPerLY        float64
+	FatigueReduction float64
+	HullFuelBonus    float64 // fuel reduction per level of the hull's racial skill
+}
+
+// DefaultHulls are representative hulls for each ship class. Exact fuel use
+// varies a little between hulls of a class.
+var DefaultHulls = map[string]Hull{
+	"black-ops":      {FuelPerLY: 700, FatigueReduction: 0.75},
+	"capital":        {FuelPerLY: 3000},
+	"jump-freighter": {FuelPerLY: 9400, FatigueReduction: 0.9, HullFuelBonus: 0.1},
+	"rorqual":        {FuelPerLY: 4000, FatigueReduction: 0.9},
+	"supercapital":   {FuelPerLY: 12500},
+}
+
+// Skills are the pilot skill levels that affect jump fuel use.
+type Skills struct {
+	FuelConservation int // Jump Fuel Conservation, 10% less fuel per level
+	HullSkill        int // e.g. Jump Freighters, for hulls with a fuel bonus
+}
+
+// Leg is a single jump along a jump-drive route. Wait is how long the pilot has
+// to sit out the previous reactivation timer before making this jump.
+type Leg struct {
+	DistanceLY   float64
+	EffectiveLY  float64
+	Isotopes     float64
+	Wait         time.Duration
+	FatigueAfter time.Duration
+	Reactivation time.Duration
+}
+
+// Plan is the fatigue and fuel profile of a whole jump-drive route.
+type Plan struct {
+	Legs         []Leg
+	Isotopes     float64
+	Wait         time.Duration
+	FinalFatigue time.Duration
+}
+
+type Calculator struct {
+	Hull   Hull
+	Skills Skills
+}
+
+func NewCalculator(hull Hull, skills Skills) Calculator {
+	return Calculator{
+		Hull:   hull,
+		Skills: skills,
+	}
+}
+
+func clampLevel(level int) float64 {
+	switch {
+	case level < 0:
+		return 0
+	case level > 5:
+		return 5
+	default:
+		return float64(level)
+	}
+}
+
+func (c Calculator) Isotopes(ly float64) float64 {
+	fuel := c.Hull.FuelPerLY * ly
+	fuel *= 1 - 0.1*clampLevel(c.Skills.FuelConservation)
+	fuel *= 1 - c.Hull.HullFuelBonus*clampLevel(c.Skills.HullSkill)
+
+	return math.Ceil(fuel)
+}
+
+func (c Calculator) EffectiveLY(ly float64) float64 {
+	return ly * (1 - c.Hull.FatigueReduction)
+}
+
+// Plan walks the legs of a route in order, starting with the given fatigue, and
+// works out the wait, fuel, fatigue, and reactivation timer of each jump. The
+// pilot is assumed to jump as soon as each reactivation timer ends.
+func (c Calculator) Plan(legsLY []float64, fatigue time.Duration) Plan {
+	p := Plan{
+		Legs: make([]Leg, len(legsLY)),
+	}
+
+	var reactivation time.Duration
+	for i, ly := range legsLY {
+		// sit out the previous reactivation timer, shedding fatigue meanwhile
+		fatigue -= reactivation
+		if fatigue < 0 {
+			fatigue = 0
+		}
+
+		eff := c.EffectiveLY(ly)
+		leg := Leg{
+			DistanceLY:  ly,
+			EffectiveLY: eff,
+			Isotopes:    c.Isotopes(ly),
+			Wait:        reactivation,
+		}
+
+		reactivation = time.Duration(float64(time.Minute) * (1 + eff))
+		if fatigue/10 > reactivation {
+			reactivation = fatigue / 10
+		}
+		if reactivation > maxReactivation {
+			reactivation = maxReactivation
+		}
+
+		base := fatigue
+		if base < minFatigue {
+			base = minFatigue
+		}
+		fatigue = time.Duration(float64(base) * (1 + eff))
+		if fatigue > maxFatigue {
+			fatigue = maxFatigue
+		}
+
+		leg.FatigueAfter = fatigue.Round(time.Second)
+		leg.Reactivation = reactivation.Round(time.Second)
+		p.Legs[i] = leg
+
+		p.Isotopes += leg.Isotopes
+		p.Wait += leg.Wait
+	}
+
+	p.FinalFatigue = fatigue.Round(time.Second)
+
+	return p
+}
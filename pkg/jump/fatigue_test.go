@@ -0,0 +1,147 @@
+package jump
+
+import (
+	"testing"
+	"time"
+)
+
+// TestPlanTimers checks single jumps against the in-game rules: the blue
+// (reactivation) timer is 1+LY minutes or a tenth of the fatigue, capped at 30
+// minutes, and the orange (fatigue) timer multiplies at least 10 minutes by
+// 1+LY, capped at 5 hours, where LY is reduced by the hull's fatigue bonus.
+func TestPlanTimers(t *testing.T) {
+	tests := []struct {
+		name             string
+		hull             string
+		ly               float64
+		fatigue          time.Duration
+		wantEffective    float64
+		wantReactivation time.Duration
+		wantFatigue      time.Duration
+	}{
+		{
+			name:             "capital from rested",
+			hull:             "capital",
+			ly:               5,
+			wantEffective:    5,
+			wantReactivation: 6 * time.Minute,
+			wantFatigue:      time.Hour,
+		},
+		{
+			name:             "black ops bonus",
+			hull:             "black-ops",
+			ly:               8,
+			wantEffective:    2,
+			wantReactivation: 3 * time.Minute,
+			wantFatigue:      30 * time.Minute,
+		},
+		{
+			name:             "jump freighter bonus",
+			hull:             "jump-freighter",
+			ly:               10,
+			wantEffective:    1,
+			wantReactivation: 2 * time.Minute,
+			wantFatigue:      20 * time.Minute,
+		},
+		{
+			name:             "blue timer from fatigue",
+			hull:             "capital",
+			ly:               1,
+			fatigue:          2 * time.Hour,
+			wantEffective:    1,
+			wantReactivation: 12 * time.Minute,
+			wantFatigue:      4 * time.Hour,
+		},
+		{
+			name:             "blue and orange caps",
+			hull:             "capital",
+			ly:               3,
+			fatigue:          8 * time.Hour,
+			wantEffective:    3,
+			wantReactivation: 30 * time.Minute,
+			wantFatigue:      5 * time.Hour,
+		},
+		{
+			name:             "orange cap",
+			hull:             "supercapital",
+			ly:               5,
+			fatigue:          time.Hour,
+			wantEffective:    5,
+			wantReactivation: 6 * time.Minute,
+			wantFatigue:      5 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCalculator(DefaultHulls[tt.hull], Skills{})
+			p := c.Plan([]float64{tt.ly}, tt.fatigue)
+			leg := p.Legs[0]
+
+			if d := leg.EffectiveLY - tt.wantEffective; d > 1e-9 || d < -1e-9 {
+				t.Errorf("got %v effective LY; want %v", leg.EffectiveLY, tt.wantEffective)
+			}
+
+			if leg.Reactivation != tt.wantReactivation {
+				t.Errorf("got reactivation %v; want %v", leg.Reactivation, tt.wantReactivation)
+			}
+
+			if leg.FatigueAfter != tt.wantFatigue || p.FinalFatigue != tt.wantFatigue {
+				t.Errorf("got fatigue %v (final %v); want %v", leg.FatigueAfter, p.FinalFatigue, tt.wantFatigue)
+			}
+		})
+	}
+}
+
+// TestPlanChain checks fatigue decays while waiting out each reactivation
+// timer and builds up over a chain of jumps.
+func TestPlanChain(t *testing.T) {
+	c := NewCalculator(DefaultHulls["capital"], Skills{})
+	p := c.Plan([]float64{5, 5, 2}, 0)
+
+	want := []Leg{
+		{Wait: 0, Reactivation: 6 * time.Minute, FatigueAfter: time.Hour},
+		{Wait: 6 * time.Minute, Reactivation: 6 * time.Minute, FatigueAfter: 5 * time.Hour}, // 54m * 6 is over the cap
+		{Wait: 6 * time.Minute, Reactivation: 29*time.Minute + 24*time.Second, FatigueAfter: 5 * time.Hour},
+	}
+
+	for i, w := range want {
+		got := p.Legs[i]
+		if got.Wait != w.Wait || got.Reactivation != w.Reactivation || got.FatigueAfter != w.FatigueAfter {
+			t.Errorf("leg %d: got wait %v, reactivation %v, fatigue %v; want %v, %v, %v",
+				i, got.Wait, got.Reactivation, got.FatigueAfter, w.Wait, w.Reactivation, w.FatigueAfter)
+		}
+	}
+
+	if p.Wait != 12*time.Minute {
+		t.Errorf("got total wait %v; want 12m", p.Wait)
+	}
+}
+
+func TestIsotopes(t *testing.T) {
+	tests := []struct {
+		name   string
+		hull   string
+		skills Skills
+		ly     float64
+		want   float64
+	}{
+		{name: "no skills", hull: "capital", ly: 5, want: 15000},
+		{name: "fuel conservation V", hull: "capital", skills: Skills{FuelConservation: 5}, ly: 5, want: 7500},
+		{name: "fuel conservation IV", hull: "black-ops", skills: Skills{FuelConservation: 4}, ly: 8, want: 3360},
+		{name: "hull bonus", hull: "jump-freighter", skills: Skills{FuelConservation: 4, HullSkill: 5}, ly: 5, want: 14100},
+		{name: "hull skill without bonus", hull: "rorqual", skills: Skills{HullSkill: 5}, ly: 2, want: 8000},
+		{name: "levels capped at V", hull: "capital", skills: Skills{FuelConservation: 7}, ly: 5, want: 7500},
+		{name: "negative levels", hull: "capital", skills: Skills{FuelConservation: -1}, ly: 5, want: 15000},
+		{name: "partial isotopes round up", hull: "capital", ly: 0.0005, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCalculator(DefaultHulls[tt.hull], tt.skills)
+			if got := c.Isotopes(tt.ly); got != tt.want {
+				t.Errorf("got %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package jump
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/gsmcwhirter/go-util/v7/errors"
+)
+
+var (
+	ErrUnknownShipClass = errors.New("unknown ship class")
+	ErrBadRange         = errors.New("bad jump range")
+)
+
+// DefaultRanges are the maximum jump ranges, in light years, of each class of
+// jump-capable ship with Jump Drive Calibration trained to V.
+var DefaultRanges = map[string]float64{
+	"black-ops":      8.0,
+	"capital":        7.0,
+	"jump-freighter": 10.0,
+	"rorqual":        10.0,
+	"supercapital":   6.0,
+}
+
+// Ranges maps ship class names to jump ranges in light years.
+type Ranges map[string]float64
+
+// NewRanges returns the default ranges with any overrides applied. Overrides
+// are given as strings so they can come straight from flags or config.
+func NewRanges(overrides map[string]string) (Ranges, error) {
+	r := Ranges{}
+	for class, ly := range DefaultRanges {
+		r[class] = ly
+	}
+
+	for class, v := range overrides {
+		ly, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not parse jump range", "class", class)
+		}
+
+		if ly <= 0 {
+			return nil, errors.Wrap(ErrBadRange, "jump range must be positive", "class", class)
+		}
+
+		r[class] = ly
+	}
+
+	return r, nil
+}
+
+func (r Ranges) Range(class string) (float64, error) {
+	ly, ok := r[class]
+	if !ok {
+		return 0, errors.Wrap(ErrUnknownShipClass, "no jump range", "class", class)
+	}
+
+	return ly, nil
+}
+
+// HullFor returns the default hull of a ship class.
+func HullFor(class string) (Hull, error) {
+	hull, ok := DefaultHulls[class]
+	if !ok {
+		return Hull{}, errors.Wrap(ErrUnknownShipClass, "no hull", "class", class)
+	}
+
+	return hull, nil
+}
+
+func (r Ranges) Classes() []string {
+	classes := make([]string, 0, len(r))
+	for class := range r {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+
+	return classes
+}
@@ -0,0 +1,164 @@
+package path
+
+import (
+	"context"
+	"sort"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/tagexpr"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+)
+
+// CentralityQuery asks how much shortest-route traffic passes through each
+// system. If any within tags or expressions are given, only routes that stay
+// in the systems carrying one of those tags or matching one of those
+// expressions count, e.g. only high-sec routes or only routes inside a region.
+// Weights makes routes from or to some systems count for more; a route counts
+// for the product of its endpoints' weights, and unlisted systems weigh 1.
+// Limit caps the number of systems returned, 0 for all of them.
+type CentralityQuery struct {
+	WithinTags  []int
+	WithinExprs []tagexpr.Expr
+	Weights     map[int]float64
+	Constraints
+	Limit int
+}
+
+// Centrality is a system's betweenness: the weighted number of shortest routes
+// through it, with routes tied for shortest sharing their weight. Relative is
+// the score as a fraction of the top system's.
+type Centrality struct {
+	System   int
+	Score    float64
+	Relative float64
+}
+
+func (q *CentralityQuery) validate(numSystems int) error {
+	for sys, w := range q.Weights {
+		if sys < 0 || sys >= numSystems {
+			return errors.Wrap(ErrBadQuery, "unknown weighted system", "system", sys)
+		}
+
+		if w < 0 {
+			return errors.Wrap(ErrBadQuery, "negative system weight", "system", sys)
+		}
+	}
+
+	if q.Limit < 0 {
+		return errors.Wrap(ErrBadQuery, "negative limit")
+	}
+
+	return nil
+}
+
+func (q *CentralityQuery) weight(sys int) float64 {
+	if w, ok := q.Weights[sys]; ok {
+		return w
+	}
+	return 1
+}
+
+// RankCentrality computes every system's betweenness with Brandes' algorithm,
+// counting each jump as one, and ranks them highest first.
+func (f *Finder) RankCentrality(ctx context.Context, q CentralityQuery) ([]Centrality, error) {
+	if err := q.validate(len(f.graph)); err != nil {
+		return nil, err
+	}
+
+	net, blocked := f.constrained(q.Constraints)
+	if len(q.WithinTags) > 0 || len(q.WithinExprs) > 0 {
+		within := f.targetSet(Query{TargetTags: q.WithinTags, TargetExprs: q.WithinExprs})
+		for sys := range f.graph {
+			if !within.Has(sys) {
+				blocked.Add(sys)
+			}
+		}
+	}
+
+	n := len(f.graph)
+	score := make([]float64, n)
+	dist := make([]int, n)
+	sigma := make([]float64, n) // shortest routes from the source
+	delta := make([]float64, n) // weight of the routes from the source passing through
+	preds := make([][]int, n)
+	for i := range dist {
+		dist[i] = -1
+	}
+
+	var queue []int
+	for s := range f.graph {
+		ws := q.weight(s)
+		if blocked.Has(s) || ws == 0 {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Wrap(err, "search aborted")
+		}
+
+		queue = append(queue[:0], s)
+		dist[s] = 0
+		sigma[s] = 1
+
+		for head := 0; head < len(queue); head++ {
+			v := queue[head]
+			net.each(v, nil, func(e Edge, _ int) {
+				w := e.To
+				if blocked.Has(w) {
+					return
+				}
+
+				if dist[w] == -1 {
+					dist[w] = dist[v] + 1
+					queue = append(queue, w)
+				}
+
+				if dist[w] == dist[v]+1 {
+					sigma[w] += sigma[v]
+					preds[w] = append(preds[w], v)
+				}
+			})
+		}
+
+		for i := len(queue) - 1; i > 0; i-- {
+			w := queue[i]
+			for _, v := range preds[w] {
+				delta[v] += sigma[v] / sigma[w] * (q.weight(w) + delta[w])
+			}
+			score[w] += ws * delta[w]
+		}
+
+		for _, v := range queue {
+			dist[v] = -1
+			sigma[v] = 0
+			delta[v] = 0
+			preds[v] = preds[v][:0]
+		}
+	}
+
+	ranked := make([]Centrality, 0, n)
+	for sys := range f.graph {
+		if !blocked.Has(sys) {
+			ranked = append(ranked, Centrality{System: sys, Score: score[sys]})
+		}
+	}
+
+	if len(ranked) == 0 {
+		return nil, errors.Wrap(ErrNoRoute, "every system is excluded")
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+
+	if top := ranked[0].Score; top > 0 {
+		for i := range ranked {
+			ranked[i].Relative = ranked[i].Score / top
+		}
+	}
+
+	if q.Limit > 0 && len(ranked) > q.Limit {
+		ranked = ranked[:q.Limit]
+	}
+
+	return ranked, nil
+}
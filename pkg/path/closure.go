@@ -0,0 +1,184 @@
+package path
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/tagexpr"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+)
+
+// ClosureQuery asks what happens to the routes between the hub systems if the
+// closed systems become impassable and the closed gates stop working, e.g.
+// when a trig invasion turns systems into dead ends. Hubs are the systems
+// listed or carrying any of the hub tags or matching any of the hub
+// expressions; if SampleSize is set, only that many of them, picked at random
+// with Seed, are checked. Limit caps the number of longer routes returned, 0
+// for all of them.
+type ClosureQuery struct {
+	Closed      []int
+	ClosedGates []int64
+
+	Hubs       []int
+	HubTags    []int
+	HubExprs   []tagexpr.Expr
+	SampleSize int
+	Seed       int64
+
+	Constraints
+	Limit int
+}
+
+// SystemPair is two hubs that could reach each other before the closure.
+type SystemPair struct {
+	From int
+	To   int
+}
+
+// Detour is a route between two hubs that gets longer with the closure in
+// place. Route is the new route.
+type Detour struct {
+	SystemPair
+	Before int
+	After  int
+	Route  Route
+}
+
+// ClosureImpact is the effect of a closure on the routes between hubs. Pairs
+// counts the hub pairs connected before the closure; those that lose their
+// route are listed in Disconnected, and those whose route gets longer in
+// Longer, longest detour first. Closed hubs are left out.
+type ClosureImpact struct {
+	Hubs         []int
+	Pairs        int
+	Disconnected []SystemPair
+	Longer       []Detour
+	Unchanged    int
+}
+
+func (q *ClosureQuery) validate(numSystems int) error {
+	if len(q.Closed) == 0 && len(q.ClosedGates) == 0 {
+		return errors.Wrap(ErrBadQuery, "no closed systems or gates")
+	}
+
+	for _, sys := range append(append([]int(nil), q.Closed...), q.Hubs...) {
+		if sys < 0 || sys >= numSystems {
+			return errors.Wrap(ErrBadQuery, "unknown system", "system", sys)
+		}
+	}
+
+	for _, g := range q.ClosedGates {
+		if g == 0 {
+			return errors.Wrap(ErrBadQuery, "unknown gate", "gate", g)
+		}
+	}
+
+	if q.SampleSize < 0 {
+		return errors.Wrap(ErrBadQuery, "negative sample size")
+	}
+
+	if q.Limit < 0 {
+		return errors.Wrap(ErrBadQuery, "negative limit")
+	}
+
+	return nil
+}
+
+func (f *Finder) hubs(q ClosureQuery) []int {
+	hubs := f.targetSet(Query{Targets: q.Hubs, TargetTags: q.HubTags, TargetExprs: q.HubExprs})
+	for _, sys := range q.Closed {
+		hubs.Remove(sys)
+	}
+
+	members := hubs.Members()
+	if q.SampleSize == 0 || q.SampleSize >= len(members) {
+		return members
+	}
+
+	rng := rand.New(rand.NewSource(q.Seed))
+	rng.Shuffle(len(members), func(i, j int) { members[i], members[j] = members[j], members[i] })
+	members = members[:q.SampleSize]
+	sort.Ints(members)
+
+	return members
+}
+
+// AnalyzeClosure compares the jumps between every pair of hubs with and
+// without the closure in place.
+func (f *Finder) AnalyzeClosure(ctx context.Context, q ClosureQuery) (ClosureImpact, error) {
+	if err := q.validate(len(f.graph)); err != nil {
+		return ClosureImpact{}, err
+	}
+
+	hubs := f.hubs(q)
+	if len(hubs) < 2 {
+		return ClosureImpact{}, errors.Wrap(ErrBadQuery, "need at least two open hubs")
+	}
+
+	closed := q.Constraints
+	closed.Avoids = append(append([]int(nil), q.Avoids...), q.Closed...)
+	closed.AvoidGates = append(append([]int64(nil), q.AvoidGates...), q.ClosedGates...)
+
+	net, blocked := f.constrained(q.Constraints, hubs...)
+	closedNet, closedBlocked := f.constrained(closed, hubs...)
+	for _, sys := range q.Closed {
+		closedBlocked.Add(sys)
+	}
+
+	type detour struct {
+		Detour
+		c candidate
+	}
+
+	var longer []detour
+	impact := ClosureImpact{Hubs: hubs}
+	for i, from := range hubs {
+		before, err := f.jumpsFrom(ctx, from, net, blocked, 0)
+		if err != nil {
+			return ClosureImpact{}, err
+		}
+
+		after, err := f.jumpsFrom(ctx, from, closedNet, closedBlocked, 0)
+		if err != nil {
+			return ClosureImpact{}, err
+		}
+
+		for _, to := range hubs[i+1:] {
+			b, a := before.dist[to], after.dist[to]
+			pair := SystemPair{From: from, To: to}
+
+			switch {
+			case b == -1:
+				continue
+			case a == -1:
+				impact.Disconnected = append(impact.Disconnected, pair)
+			case a > b:
+				c, _ := after.path(to)
+				longer = append(longer, detour{
+					Detour: Detour{SystemPair: pair, Before: b, After: a},
+					c:      c,
+				})
+			default:
+				impact.Unchanged++
+			}
+
+			impact.Pairs++
+		}
+	}
+
+	sort.SliceStable(longer, func(i, j int) bool {
+		return longer[i].After-longer[i].Before > longer[j].After-longer[j].Before
+	})
+
+	if q.Limit > 0 && len(longer) > q.Limit {
+		longer = longer[:q.Limit]
+	}
+
+	for _, d := range longer {
+		d.Route = f.newRoute(d.c, nil, nil)
+		impact.Longer = append(impact.Longer, d.Detour)
+	}
+
+	return impact, nil
+}
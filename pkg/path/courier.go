@@ -0,0 +1,229 @@
+package path
+
+import (
+	"context"
+
+	"github.com/gsmcwhirter/go-util/v7/errors"
+)
+
+// Delivery is cargo to collect in the pickup system and bring to the dropoff.
+type Delivery struct {
+	Pickup  int
+	Dropoff int
+}
+
+// CourierQuery asks for the shortest route from Start that makes every pickup
+// before its dropoff. HighSecOnly keeps the route, and so every delivery, in
+// high-sec.
+type CourierQuery struct {
+	Start       int
+	Deliveries  []Delivery
+	HighSecOnly bool
+	Constraints
+}
+
+// CourierStop is a system where the route picks up or drops off cargo, by
+// index into the query's deliveries.
+type CourierStop struct {
+	System   int
+	Pickups  []int
+	Dropoffs []int
+}
+
+// CourierPlan is the order to make the pickups and dropoffs in, and the route
+// through them.
+type CourierPlan struct {
+	Stops []CourierStop
+	Route Route
+}
+
+func (q *CourierQuery) validate(numSystems int) error {
+	if q.Start < 0 || q.Start >= numSystems {
+		return errors.Wrap(ErrBadQuery, "unknown start system", "system", q.Start)
+	}
+
+	if len(q.Deliveries) == 0 {
+		return errors.Wrap(ErrBadQuery, "no deliveries")
+	}
+
+	for i, d := range q.Deliveries {
+		if d.Pickup < 0 || d.Pickup >= numSystems || d.Dropoff < 0 || d.Dropoff >= numSystems {
+			return errors.Wrap(ErrBadQuery, "unknown delivery system", "delivery", i)
+		}
+	}
+
+	return nil
+}
+
+// courierEvent is a pickup or dropoff of one delivery.
+type courierEvent struct {
+	delivery int
+	pickup   bool
+}
+
+type courierPlanner struct {
+	q    CourierQuery
+	maps map[int]*jumpMap
+}
+
+func (p *courierPlanner) system(e courierEvent) int {
+	if e.pickup {
+		return p.q.Deliveries[e.delivery].Pickup
+	}
+	return p.q.Deliveries[e.delivery].Dropoff
+}
+
+func (p *courierPlanner) jumps(from, to int) int {
+	if d := p.maps[from].dist[to]; d != -1 {
+		return d
+	}
+	return unreachableLeg
+}
+
+func (p *courierPlanner) length(seq []courierEvent) int {
+	total := 0
+	prev := p.q.Start
+	for _, e := range seq {
+		sys := p.system(e)
+		total += p.jumps(prev, sys)
+		prev = sys
+	}
+
+	return total
+}
+
+// insert returns seq with delivery d's pickup and dropoff added wherever
+// they make the route shortest.
+func (p *courierPlanner) insert(seq []courierEvent, d int) ([]courierEvent, int) {
+	var best []courierEvent
+	bestLen := -1
+
+	pickup := courierEvent{delivery: d, pickup: true}
+	dropoff := courierEvent{delivery: d}
+
+	for i := 0; i <= len(seq); i++ {
+		for j := i; j <= len(seq); j++ {
+			trial := make([]courierEvent, 0, len(seq)+2)
+			trial = append(trial, seq[:i]...)
+			trial = append(trial, pickup)
+			trial = append(trial, seq[i:j]...)
+			trial = append(trial, dropoff)
+			trial = append(trial, seq[j:]...)
+
+			if l := p.length(trial); bestLen == -1 || l < bestLen {
+				best, bestLen = trial, l
+			}
+		}
+	}
+
+	return best, bestLen
+}
+
+// without returns seq with delivery d's events taken out.
+func without(seq []courierEvent, d int) []courierEvent {
+	ret := make([]courierEvent, 0, len(seq))
+	for _, e := range seq {
+		if e.delivery != d {
+			ret = append(ret, e)
+		}
+	}
+
+	return ret
+}
+
+// PlanCourier finds a short route through every pickup and dropoff, building
+// it up by cheapest insertion and then moving deliveries one at a time while
+// that shortens it.
+func (f *Finder) PlanCourier(ctx context.Context, q CourierQuery) (CourierPlan, error) {
+	if err := q.validate(len(f.graph)); err != nil {
+		return CourierPlan{}, err
+	}
+
+	keep := []int{q.Start}
+	for _, d := range q.Deliveries {
+		keep = append(keep, d.Pickup, d.Dropoff)
+	}
+
+	net, blocked := f.constrained(q.Constraints, keep...)
+	if q.HighSecOnly {
+		for sys := range f.graph {
+			if sys >= len(f.secStatus) || f.secStatus[sys] != "high" {
+				blocked.Add(sys)
+			}
+		}
+	}
+
+	p := &courierPlanner{q: q, maps: map[int]*jumpMap{}}
+	for _, sys := range keep {
+		if _, ok := p.maps[sys]; ok {
+			continue
+		}
+
+		if blocked.Has(sys) {
+			return CourierPlan{}, errors.Wrap(ErrNoRoute, "delivery system is blocked", "system", sys)
+		}
+
+		m, err := f.jumpsFrom(ctx, sys, net, blocked, 0)
+		if err != nil {
+			return CourierPlan{}, err
+		}
+		p.maps[sys] = m
+	}
+
+	for _, sys := range keep {
+		if p.maps[q.Start].dist[sys] == -1 {
+			return CourierPlan{}, errors.Wrap(ErrNoRoute, "delivery system cannot be reached", "system", sys)
+		}
+	}
+
+	var seq []courierEvent
+	length := 0
+	for d := range q.Deliveries {
+		seq, length = p.insert(seq, d)
+	}
+
+	for improved := true; improved; {
+		if err := ctx.Err(); err != nil {
+			return CourierPlan{}, errors.Wrap(err, "search aborted")
+		}
+
+		improved = false
+		for d := range q.Deliveries {
+			if moved, l := p.insert(without(seq, d), d); l < length {
+				seq, length = moved, l
+				improved = true
+			}
+		}
+	}
+
+	plan := CourierPlan{}
+	c := candidate{systems: []int{q.Start}}
+	prev := q.Start
+	for _, e := range seq {
+		sys := p.system(e)
+		if sys != prev || len(plan.Stops) == 0 {
+			leg, ok := p.maps[prev].path(sys)
+			if !ok {
+				return CourierPlan{}, errors.Wrap(ErrNoRoute, "no route between stops", "from", prev, "to", sys)
+			}
+
+			c.systems = append(c.systems, leg.systems[1:]...)
+			c.edges = append(c.edges, leg.edges...)
+			c.cost += leg.cost
+			plan.Stops = append(plan.Stops, CourierStop{System: sys})
+			prev = sys
+		}
+
+		stop := &plan.Stops[len(plan.Stops)-1]
+		if e.pickup {
+			stop.Pickups = append(stop.Pickups, e.delivery)
+		} else {
+			stop.Dropoffs = append(stop.Dropoffs, e.delivery)
+		}
+	}
+
+	plan.Route = f.newRoute(c, nil, nil)
+	plan.Route.ETA, plan.Route.WarpAU = f.eta(c.edges, q.travel())
+
+	return plan, nil
+}
@@ -0,0 +1,127 @@
+package path
+
+import (
+	"context"
+	stderr "errors"
+	"fmt"
+
+	"github.com/gonum/stat/combin"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/bitset"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/tagexpr"
+)
+
+// maxCutSize bounds how many avoids findCut will try lifting at once before
+// giving up and lifting all of them.
+const maxCutSize = 3
+
+// Cut is a set of hard avoids that, once lifted, let a route through, along
+// with the length of the route that results.
+type Cut struct {
+	Avoids     []int
+	AvoidTags  []int
+	AvoidExprs []tagexpr.Expr
+	Jumps      int
+}
+
+// NoRouteError is returned by Route when no route exists. It wraps ErrNoRoute
+// and, when lifting some of the query's hard avoids would make a route
+// possible, carries the smallest such Cut that could be found.
+type NoRouteError struct {
+	Cut   *Cut
+	cause error
+}
+
+func (e *NoRouteError) Error() string {
+	if e.Cut == nil {
+		return e.cause.Error()
+	}
+
+	return fmt.Sprintf("%s (lifting %d avoids gives a %d jump route)", e.cause.Error(), len(e.Cut.Avoids)+len(e.Cut.AvoidTags)+len(e.Cut.AvoidExprs), e.Cut.Jumps)
+}
+
+func (e *NoRouteError) Unwrap() error {
+	return e.cause
+}
+
+// findCut looks for the smallest set of hard avoids blocking every route,
+// preferring the set that gives the shortest route once lifted. Prefer-not
+// filters have already been dropped by the time this runs, so they are ignored.
+func (f *Finder) findCut(ctx context.Context, q Query, targets bitset.Set, hard []filter) (*Cut, error) {
+	numItems := len(hard)
+	if numItems == 0 {
+		return nil, nil
+	}
+
+	try := func(lifted []bool) (*Cut, error) {
+		cut := &Cut{}
+		kept := make([]filter, 0, numItems)
+
+		for i, fl := range hard {
+			switch {
+			case !lifted[i]:
+				kept = append(kept, fl)
+			case fl.expr != nil:
+				cut.AvoidExprs = append(cut.AvoidExprs, fl.expr)
+			case fl.tag >= 0:
+				cut.AvoidTags = append(cut.AvoidTags, fl.tag)
+			default:
+				cut.Avoids = append(cut.Avoids, fl.system)
+			}
+		}
+
+		single := q
+		single.Limit = 1
+		routes, err := f.findAllShortestRoutes(ctx, single, targets, f.blockedSet(q, kept))
+		if err != nil {
+			return nil, err
+		}
+
+		cut.Jumps = len(routes[0].systems) - 1
+
+		return cut, nil
+	}
+
+	for k := 1; k <= numItems && k <= maxCutSize; k++ {
+		var best *Cut
+
+		for _, combo := range combin.Combinations(numItems, k) {
+			lifted := make([]bool, numItems)
+			for _, idx := range combo {
+				lifted[idx] = true
+			}
+
+			cut, err := try(lifted)
+			if stderr.Is(err, ErrNoRoute) {
+				continue
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			if best == nil || cut.Jumps < best.Jumps {
+				best = cut
+			}
+		}
+
+		if best != nil {
+			return best, nil
+		}
+	}
+
+	if numItems <= maxCutSize {
+		return nil, nil
+	}
+
+	lifted := make([]bool, numItems)
+	for i := range lifted {
+		lifted[i] = true
+	}
+
+	cut, err := try(lifted)
+	if stderr.Is(err, ErrNoRoute) {
+		return nil, nil
+	}
+
+	return cut, err
+}
@@ -0,0 +1,127 @@
+package path
+
+import (
+	"context"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/bitset"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/tagexpr"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+)
+
+// jumpMap holds the fewest jumps from one system to every other, and the edge
+// each system is first reached by. Unreachable systems have a distance of -1.
+type jumpMap struct {
+	source int
+	dist   []int
+	parent []Edge
+}
+
+// jumpsFrom searches breadth-first out of source, counting every edge in the
+// network as one jump, up to maxJumps (0 for no limit). Blocked systems are
+// never entered, though the source itself may be blocked.
+func (f *Finder) jumpsFrom(ctx context.Context, source int, net *network, blocked bitset.Set, maxJumps int) (*jumpMap, error) {
+	m := &jumpMap{
+		source: source,
+		dist:   make([]int, len(f.graph)),
+		parent: make([]Edge, len(f.graph)),
+	}
+	for i := range m.dist {
+		m.dist[i] = -1
+	}
+	m.dist[source] = 0
+
+	frontier := []int{source}
+	for depth := 1; len(frontier) > 0 && (maxJumps == 0 || depth <= maxJumps); depth++ {
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Wrap(err, "search aborted")
+		}
+
+		var next []int
+		for _, sys := range frontier {
+			net.each(sys, nil, func(e Edge, _ int) {
+				if m.dist[e.To] != -1 || blocked.Has(e.To) {
+					return
+				}
+
+				m.dist[e.To] = depth
+				m.parent[e.To] = e
+				next = append(next, e.To)
+			})
+		}
+
+		frontier = next
+	}
+
+	return m, nil
+}
+
+// path returns the systems and edges from the map's source to sys, or false if
+// sys was not reached.
+func (m *jumpMap) path(sys int) (candidate, bool) {
+	if m.dist[sys] == -1 {
+		return candidate{}, false
+	}
+
+	c := candidate{
+		systems: make([]int, m.dist[sys]+1),
+		edges:   make([]Edge, m.dist[sys]),
+		rank:    rank{cost: m.dist[sys]},
+	}
+
+	for i := m.dist[sys]; i > 0; i-- {
+		e := m.parent[sys]
+		c.systems[i] = sys
+		c.edges[i-1] = e
+		sys = e.From
+	}
+	c.systems[0] = sys
+
+	return c, true
+}
+
+// Constraints are the avoid lists and extra edges shared by the searches that
+// work from jump distances, such as rendezvous and tour planning.
+type Constraints struct {
+	Avoids     []int
+	AvoidGates []int64
+	AvoidTags  []int
+	AvoidExprs []tagexpr.Expr
+
+	UseBridges   bool
+	BridgeOwners []string
+	Wormholes    []Edge
+
+	// Travel estimates the ETAs of the routes returned (DefaultTravel if
+	// unset). It does not change which routes are found.
+	Travel *Travel
+}
+
+func (c Constraints) query() Query {
+	return Query{
+		Avoids:       c.Avoids,
+		AvoidGates:   c.AvoidGates,
+		AvoidTags:    c.AvoidTags,
+		AvoidExprs:   c.AvoidExprs,
+		UseBridges:   c.UseBridges,
+		BridgeOwners: c.BridgeOwners,
+		Wormholes:    c.Wormholes,
+		Travel:       c.Travel,
+	}
+}
+
+func (c Constraints) travel() Travel {
+	if c.Travel == nil {
+		return DefaultTravel
+	}
+
+	return *c.Travel
+}
+
+// constrained returns the edges the constraints allow and the systems they block,
+// leaving the systems in keep unblocked.
+func (f *Finder) constrained(c Constraints, keep ...int) (*network, bitset.Set) {
+	q := c.query()
+	q.Targets = keep
+
+	return f.network(q), f.blockedSet(q, f.filters(c.Avoids, c.AvoidTags, c.AvoidExprs))
+}
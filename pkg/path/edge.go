@@ -0,0 +1,75 @@
+package path
+
+type EdgeKind int
+
+const (
+	Stargate EdgeKind = iota
+	JumpBridge
+	Wormhole
+)
+
+func (k EdgeKind) String() string {
+	switch k {
+	case Stargate:
+		return "stargate"
+	case JumpBridge:
+		return "jump-bridge"
+	case Wormhole:
+		return "wormhole"
+	default:
+		return "unknown"
+	}
+}
+
+// Edge is a single way of getting from one system to another. Label carries
+// extra information about the edge, such as a stargate's name, a jump bridge's
+// owner, or a wormhole's ID. Gate and ToGate are the stargates jumped from and
+// arrived at, when they are known.
+type Edge struct {
+	From   int
+	To     int
+	Kind   EdgeKind
+	Label  string
+	Gate   int64
+	ToGate int64
+}
+
+// SetGates names the stargates behind the system-to-system graph. Each edge
+// fills in the first unnamed stargate from its From system to its To system;
+// edges the graph does not have are ignored. This is not safe to call while
+// searches are running.
+func (f *Finder) SetGates(edges []Edge) {
+	for _, e := range edges {
+		if e.From < 0 || e.From >= len(f.gates) {
+			continue
+		}
+
+		for i, g := range f.gates[e.From] {
+			if g.To != e.To || g.Gate != 0 {
+				continue
+			}
+
+			f.gates[e.From][i].Label = e.Label
+			f.gates[e.From][i].Gate = e.Gate
+			f.gates[e.From][i].ToGate = e.ToGate
+			break
+		}
+	}
+}
+
+// SetBridges replaces the jump bridge network. Each edge is one-way, so a
+// bridge should be given in both directions. This is not safe to call while
+// searches are running.
+func (f *Finder) SetBridges(edges []Edge) {
+	bridges := make([][]Edge, len(f.graph))
+	for _, e := range edges {
+		if e.From < 0 || e.From >= len(f.graph) || e.To < 0 || e.To >= len(f.graph) {
+			continue
+		}
+
+		e.Kind = JumpBridge
+		bridges[e.From] = append(bridges[e.From], e)
+	}
+
+	f.bridges = bridges
+}
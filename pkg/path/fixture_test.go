@@ -0,0 +1,59 @@
+package path
+
+// Systems in the test fixture. Two 2-jump routes run from A to D, one through
+// low-sec B and one through null-sec C, and a 4-jump high-sec route runs
+// through F, G, and H. E hangs off D, and J sits behind low-sec I.
+const (
+	sysA = iota
+	sysB
+	sysC
+	sysD
+	sysE
+	sysF
+	sysG
+	sysH
+	sysI
+	sysJ
+)
+
+const (
+	tagHigh = iota
+	tagLow
+	tagNull
+)
+
+func newTestFinder() *Finder {
+	graph := [][]int{
+		sysA: {sysB, sysC, sysF},
+		sysB: {sysA, sysD},
+		sysC: {sysA, sysD},
+		sysD: {sysB, sysC, sysE, sysH, sysI},
+		sysE: {sysD},
+		sysF: {sysA, sysG},
+		sysG: {sysF, sysH},
+		sysH: {sysG, sysD},
+		sysI: {sysD, sysJ},
+		sysJ: {sysI},
+	}
+
+	tags := [][]int{
+		sysA: {tagHigh},
+		sysB: {tagLow},
+		sysC: {tagNull},
+		sysD: {tagHigh},
+		sysE: {tagHigh},
+		sysF: {tagHigh},
+		sysG: {tagHigh},
+		sysH: {tagHigh},
+		sysI: {tagLow},
+		sysJ: {tagHigh},
+	}
+
+	sec := make([]string, len(graph))
+	names := []string{"high", "low", "null"}
+	for sys, t := range tags {
+		sec[sys] = names[t[0]]
+	}
+
+	return NewFinder(graph, tags, sec, nil)
+}
@@ -0,0 +1,329 @@
+package path
+
+import (
+	"container/heap"
+	"context"
+	"math"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/bitset"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/system"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+)
+
+type JumpObjective int
+
+const (
+	FewestJumps JumpObjective = iota
+	ShortestDistance
+	LeastFatigue
+)
+
+func ParseJumpObjective(s string) (JumpObjective, error) {
+	switch s {
+	case "", "jumps":
+		return FewestJumps, nil
+	case "distance":
+		return ShortestDistance, nil
+	case "fatigue":
+		return LeastFatigue, nil
+	default:
+		return FewestJumps, errors.Wrap(ErrBadQuery, "unknown jump objective", "objective", s)
+	}
+}
+
+// JumpQuery describes a jump-drive route search. Jumps may only land in
+// cyno-legal systems (low and null sec, outside any systems set with SetNoCyno)
+// within RangeLY light years.
+// FatigueReduction is the hull's fatigue bonus, used by the LeastFatigue
+// objective.
+type JumpQuery struct {
+	Sources          []int
+	Targets          []int
+	RangeLY          float64
+	Avoids           []int
+	AvoidTags        []int
+	Objective        JumpObjective
+	FatigueReduction float64
+}
+
+// JumpRoute is a jump-drive route, with the distance of each leg in light years.
+type JumpRoute struct {
+	Systems    []int
+	Source     int
+	Jumps      int
+	Legs       []float64
+	DistanceLY float64
+}
+
+// SetNoCyno marks the systems cynosural fields cannot be lit in despite their
+// security, such as Pochven. This is not safe to call while searches are
+// running.
+func (f *Finder) SetNoCyno(systems []int) {
+	f.noCyno = bitset.New(len(f.graph))
+	for _, sys := range systems {
+		if sys >= 0 && sys < len(f.graph) {
+			f.noCyno.Add(sys)
+		}
+	}
+}
+
+func (f *Finder) cynoLegal(sys int) bool {
+	if sys >= len(f.secStatus) || f.noCyno.Has(sys) {
+		return false
+	}
+
+	switch f.secStatus[sys] {
+	case "low", "null":
+		return true
+	default:
+		return false
+	}
+}
+
+// HasCoordinates reports whether any system's position is known, which jump
+// drive routes need.
+func (f *Finder) HasCoordinates() bool {
+	for _, p := range f.positions {
+		if p != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *Finder) distanceLY(a, b int) float64 {
+	return f.positions[a].DistanceLY(*f.positions[b])
+}
+
+func (q *JumpQuery) validate(f *Finder) error {
+	if !f.HasCoordinates() {
+		return errors.Wrap(ErrNoCoordinates, "jump routes need system coordinates")
+	}
+
+	if len(q.Sources) == 0 {
+		return errors.Wrap(ErrBadQuery, "no source systems")
+	}
+
+	if len(q.Targets) == 0 {
+		return errors.Wrap(ErrBadQuery, "no target systems")
+	}
+
+	if q.RangeLY <= 0 {
+		return errors.Wrap(ErrBadQuery, "jump range must be positive")
+	}
+
+	for _, s := range q.Sources {
+		if s < 0 || s >= len(f.graph) {
+			return errors.Wrap(ErrBadQuery, "unknown source system", "system", s)
+		}
+
+		if f.positions[s] == nil {
+			return errors.Wrap(ErrBadQuery, "source system has no coordinates", "system", s)
+		}
+	}
+
+	for _, t := range q.Targets {
+		if t < 0 || t >= len(f.graph) {
+			return errors.Wrap(ErrBadQuery, "unknown target system", "system", t)
+		}
+
+		if f.positions[t] == nil {
+			return errors.Wrap(ErrBadQuery, "target system has no coordinates", "system", t)
+		}
+
+		if !f.cynoLegal(t) {
+			return errors.Wrap(ErrBadQuery, "cannot jump into target system", "system", t)
+		}
+	}
+
+	return nil
+}
+
+// jumpGrid buckets the systems a jump may land in by cubes one jump range on a
+// side, so finding the systems in range only needs to look at 27 cubes.
+type jumpGrid struct {
+	size  float64
+	cells map[[3]int][]int
+}
+
+func (f *Finder) newJumpGrid(rangeLY float64, blocked bitset.Set) *jumpGrid {
+	g := &jumpGrid{
+		size:  rangeLY * system.MetersPerLY,
+		cells: map[[3]int][]int{},
+	}
+
+	for sys, p := range f.positions {
+		if p == nil || !f.cynoLegal(sys) || blocked.Has(sys) {
+			continue
+		}
+
+		c := g.cell(*p)
+		g.cells[c] = append(g.cells[c], sys)
+	}
+
+	return g
+}
+
+func (g *jumpGrid) cell(p system.Position) [3]int {
+	return [3]int{
+		int(math.Floor(p.X / g.size)),
+		int(math.Floor(p.Y / g.size)),
+		int(math.Floor(p.Z / g.size)),
+	}
+}
+
+func (g *jumpGrid) near(p system.Position) [][]int {
+	c := g.cell(p)
+	near := make([][]int, 0, 27)
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			for dz := -1; dz <= 1; dz++ {
+				if systems, ok := g.cells[[3]int{c[0] + dx, c[1] + dy, c[2] + dz}]; ok {
+					near = append(near, systems)
+				}
+			}
+		}
+	}
+
+	return near
+}
+
+// jumpCost orders search states by a primary and then a secondary cost; which
+// of jumps and distance is primary depends on the query's objective.
+type jumpCost struct {
+	primary, secondary float64
+}
+
+func (c jumpCost) less(o jumpCost) bool {
+	if c.primary != o.primary {
+		return c.primary < o.primary
+	}
+
+	return c.secondary < o.secondary
+}
+
+type jumpItem struct {
+	system int
+	cost   jumpCost
+}
+
+type jumpHeap []jumpItem
+
+func (h jumpHeap) Len() int            { return len(h) }
+func (h jumpHeap) Less(i, j int) bool  { return h[i].cost.less(h[j].cost) }
+func (h jumpHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *jumpHeap) Push(x interface{}) { *h = append(*h, x.(jumpItem)) }
+func (h *jumpHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// FindJumpRoute finds the best jump-drive route from any of the query's sources
+// to any of its targets, by fewest jumps (then least distance), by least
+// distance (then fewest jumps), or by least fatigue (then fewest jumps).
+func (f *Finder) FindJumpRoute(ctx context.Context, q JumpQuery) (JumpRoute, error) {
+	if err := q.validate(f); err != nil {
+		return JumpRoute{}, err
+	}
+
+	blocked := f.blockedSet(Query{Targets: q.Targets}, f.filters(q.Avoids, q.AvoidTags, nil))
+	grid := f.newJumpGrid(q.RangeLY, blocked)
+
+	targets := bitset.New(len(f.graph))
+	for _, t := range q.Targets {
+		targets.Add(t)
+	}
+
+	costs := make([]*jumpCost, len(f.graph))
+	preds := make([]int, len(f.graph))
+	done := bitset.New(len(f.graph))
+
+	h := &jumpHeap{}
+	for _, s := range q.Sources {
+		costs[s] = &jumpCost{}
+		preds[s] = -1
+		heap.Push(h, jumpItem{system: s})
+	}
+
+	for steps := 0; h.Len() > 0; steps++ {
+		if steps%256 == 0 {
+			if err := ctx.Err(); err != nil {
+				return JumpRoute{}, errors.Wrap(err, "search aborted")
+			}
+		}
+
+		item := heap.Pop(h).(jumpItem)
+		curr := item.system
+		if done.Has(curr) {
+			continue
+		}
+		done.Add(curr)
+
+		if targets.Has(curr) && preds[curr] != -1 {
+			return f.newJumpRoute(curr, preds), nil
+		}
+
+		for _, cell := range grid.near(*f.positions[curr]) {
+			for _, next := range cell {
+				if next == curr || done.Has(next) {
+					continue
+				}
+
+				ly := f.distanceLY(curr, next)
+				if ly > q.RangeLY {
+					continue
+				}
+
+				var cost jumpCost
+				switch q.Objective {
+				case ShortestDistance:
+					cost = jumpCost{primary: item.cost.primary + ly, secondary: item.cost.secondary + 1}
+				case LeastFatigue:
+					// fatigue grows by a factor of (1 + effective ly) per jump, so
+					// summing the logs ranks routes by their final fatigue
+					cost = jumpCost{primary: item.cost.primary + math.Log1p(ly*(1-q.FatigueReduction)), secondary: item.cost.secondary + 1}
+				default:
+					cost = jumpCost{primary: item.cost.primary + 1, secondary: item.cost.secondary + ly}
+				}
+
+				if costs[next] != nil && !cost.less(*costs[next]) {
+					continue
+				}
+
+				costs[next] = &cost
+				preds[next] = curr
+				heap.Push(h, jumpItem{system: next, cost: cost})
+			}
+		}
+	}
+
+	return JumpRoute{}, errors.Wrap(ErrNoRoute, "no jump route in range", "range_ly", q.RangeLY)
+}
+
+func (f *Finder) newJumpRoute(end int, preds []int) JumpRoute {
+	var systems []int
+	for sys := end; sys != -1; sys = preds[sys] {
+		systems = append(systems, sys)
+	}
+
+	for i, j := 0, len(systems)-1; i < j; i, j = i+1, j-1 {
+		systems[i], systems[j] = systems[j], systems[i]
+	}
+
+	r := JumpRoute{
+		Systems: systems,
+		Source:  systems[0],
+		Jumps:   len(systems) - 1,
+		Legs:    make([]float64, len(systems)-1),
+	}
+
+	for i := 1; i < len(systems); i++ {
+		r.Legs[i-1] = f.distanceLY(systems[i-1], systems[i])
+		r.DistanceLY += r.Legs[i-1]
+	}
+
+	return r
+}
@@ -0,0 +1,71 @@
+package path
+
+import (
+	"context"
+	stderr "errors"
+	"reflect"
+	"testing"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/system"
+)
+
+// TestFindJumpRouteNoCyno checks jumps skip systems marked with SetNoCyno,
+// even when they are null-sec and in range.
+func TestFindJumpRouteNoCyno(t *testing.T) {
+	ly := func(x float64) *system.Position { return &system.Position{X: x * system.MetersPerLY} }
+
+	// a line of null-sec systems 4 LY apart, with a 5 LY jump range
+	positions := []*system.Position{ly(0), ly(4), ly(8), ly(12)}
+	sec := []string{"null", "null", "null", "null"}
+	graph := make([][]int, len(positions))
+
+	tests := []struct {
+		name   string
+		noCyno []int
+		want   []int
+		err    error
+	}{
+		{name: "all cyno-legal", want: []int{0, 1, 2, 3}},
+		{name: "target banned", noCyno: []int{3}, err: ErrBadQuery},
+		{name: "midpoint banned", noCyno: []int{2}, err: ErrNoRoute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFinder(graph, nil, sec, positions)
+			f.SetNoCyno(tt.noCyno)
+
+			route, err := f.FindJumpRoute(context.Background(), JumpQuery{
+				Sources: []int{0},
+				Targets: []int{3},
+				RangeLY: 5,
+			})
+
+			if tt.err != nil {
+				if !stderr.Is(err, tt.err) {
+					t.Fatalf("got %v, %v; want %v", route.Systems, err, tt.err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(route.Systems, tt.want) {
+				t.Errorf("got %v; want %v", route.Systems, tt.want)
+			}
+		})
+	}
+}
+
+// TestFindJumpRouteNoCoordinates checks jump routes fail outright on system
+// data generated without coordinates.
+func TestFindJumpRouteNoCoordinates(t *testing.T) {
+	f := newTestFinder()
+
+	_, err := f.FindJumpRoute(context.Background(), JumpQuery{Sources: []int{sysA}, Targets: []int{sysI}, RangeLY: 5})
+	if !stderr.Is(err, ErrNoCoordinates) {
+		t.Errorf("got %v; want ErrNoCoordinates", err)
+	}
+}
@@ -0,0 +1,135 @@
+package path
+
+import (
+	"context"
+	stderr "errors"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/bitset"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+)
+
+// NearestRoutes finds routes to the q.Limit nearest distinct target systems,
+// one route each: those the fewest jumps away, and of those the cheapest,
+// nearest first. Fewer are returned if fewer can be reached. Prefer-not
+// filters are only dropped when no target can be reached at all, in which case
+// the single best route found by Route is returned.
+func (f *Finder) NearestRoutes(ctx context.Context, q Query) ([]Route, error) {
+	if err := q.validate(f); err != nil {
+		return nil, err
+	}
+
+	if q.Limit <= 0 {
+		return nil, errors.Wrap(ErrBadQuery, "need a limit on the number of systems")
+	}
+
+	targets := f.targetSet(q)
+	if err := startsAtTarget(q, targets); err != nil {
+		return nil, err
+	}
+
+	hard := f.filters(q.Avoids, q.AvoidTags, q.AvoidExprs)
+	soft := f.filters(nil, q.PreferNotTags, q.PreferNotExprs)
+
+	routes, err := f.findNearestRoutes(ctx, q, targets, f.blockedSet(q, hard, soft))
+	if err == nil {
+		return f.addETAs(f.newRoutes(routes, soft, nil), q.travel()), nil
+	}
+
+	if !stderr.Is(err, ErrNoRoute) {
+		return nil, err
+	}
+
+	q.Limit = 1
+	return f.route(ctx, q, targets)
+}
+
+// findNearestRoutes runs one search out of all the sources at once, ranking
+// labels by jumps ahead of the query's priorities and cost, and settles
+// targets in that order until q.Limit distinct ones have been reached. Routes
+// may pass through targets settled earlier on their way to later ones.
+func (f *Finder) findNearestRoutes(ctx context.Context, q Query, targets, blocked bitset.Set) ([]candidate, error) {
+	every := bitset.New(len(f.graph))
+	for sys := range f.graph {
+		every.Add(sys)
+	}
+
+	s := &search{
+		targets:    targets,
+		blocked:    blocked,
+		budgets:    f.budgets(q),
+		priorities: []bitset.Set{every}, // counts each jump
+		net:        f.network(q),
+		maxJumps:   q.MaxJumps,
+		limit:      1,
+	}
+
+	for _, p := range q.Priorities {
+		s.priorities = append(s.priorities, f.prioritySet(p))
+	}
+
+	labels := make([][]*label, len(f.graph))
+	queue := newCostQueue()
+
+	for _, start := range q.Sources {
+		if targets.Has(start) {
+			continue
+		}
+
+		origin := &label{
+			system: start,
+			rank:   rank{tiers: make([]int, len(s.priorities))},
+			usage:  make([]int, len(s.budgets)),
+		}
+		labels[start] = append(labels[start], origin)
+		queue.push(origin)
+	}
+
+	settled := bitset.New(len(f.graph))
+	var found []*label
+
+	for len(found) < q.Limit {
+		bucket, ok := queue.pop()
+		if !ok {
+			break
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Wrap(err, "search aborted")
+		}
+
+		for _, curr := range bucket {
+			if s.dominated(labels[curr.system], curr) {
+				continue
+			}
+
+			if targets.Has(curr.system) && !settled.Has(curr.system) && len(found) < q.Limit {
+				settled.Add(curr.system)
+				found = append(found, curr)
+			}
+
+			s.net.each(curr.system, curr.via, func(e Edge, w int) {
+				if next := f.extend(labels, s, curr, e, w); next != nil {
+					queue.push(next)
+				}
+			})
+		}
+	}
+
+	if len(found) == 0 {
+		return nil, errors.Wrap(ErrNoRoute, "could not find route")
+	}
+
+	routes := make([]candidate, 0, len(found))
+	for _, end := range found {
+		routes = appendRoutesTo(routes, end, len(routes)+1)
+	}
+
+	for i := range routes {
+		routes[i].tiers = routes[i].tiers[1:] // drop the jump count
+		if len(routes[i].tiers) == 0 {
+			routes[i].tiers = nil
+		}
+	}
+
+	return routes, nil
+}
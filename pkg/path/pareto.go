@@ -0,0 +1,257 @@
+package path
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/bitset"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/tagexpr"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+)
+
+// Measure is a risk totalled along a route: the jumps into systems carrying
+// Tag, or matching Expr if it is set, or if Scores is set, the sum of the
+// scores of the systems jumped into, such as their danger. Name labels it in
+// trade-offs.
+type Measure struct {
+	Name   string
+	Tag    int
+	Expr   tagexpr.Expr
+	Scores map[int]float64
+}
+
+// ParetoRoute is a route no other route beats on cost and every measure at
+// once. Measures holds its totals in the order the measures were given.
+type ParetoRoute struct {
+	Route    Route
+	Measures []float64
+}
+
+// Tradeoff describes the route by its jumps and measure totals, such as
+// "12 jumps / 3 low / 0 null".
+func (r ParetoRoute) Tradeoff(measures []Measure) string {
+	parts := []string{fmt.Sprintf("%d jumps", r.Route.Jumps)}
+	for i, m := range measures {
+		if m.Scores != nil {
+			parts = append(parts, fmt.Sprintf("%.1f %s", r.Measures[i], m.Name))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d %s", int(r.Measures[i]), m.Name))
+		}
+	}
+
+	return strings.Join(parts, " / ")
+}
+
+// paretoMaxLabels caps the partial routes a Pareto search keeps, since fine
+// grained measures like danger scores can make the frontier grow quickly.
+const paretoMaxLabels = 500000
+
+// ErrSearchTooLarge is returned when a search would need more memory than it
+// is allowed. A tighter query, e.g. with max jumps, may still work.
+var ErrSearchTooLarge = errors.New("search too large")
+
+type paretoLabel struct {
+	system   int
+	cost     int
+	jumps    int
+	measures []float64
+	pred     *paretoLabel
+	edge     Edge
+}
+
+// dominates reports whether l is no worse than o in every way, so o need not
+// be explored. Jumps only count when they are limited.
+func (l *paretoLabel) dominates(o *paretoLabel, maxJumps int) bool {
+	if l.cost > o.cost || (maxJumps > 0 && l.jumps > o.jumps) {
+		return false
+	}
+
+	for i, m := range l.measures {
+		if m > o.measures[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// before orders labels by cost and then each measure in turn.
+func (l *paretoLabel) before(o *paretoLabel) bool {
+	if l.cost != o.cost {
+		return l.cost < o.cost
+	}
+
+	for i, m := range l.measures {
+		if m != o.measures[i] {
+			return m < o.measures[i]
+		}
+	}
+
+	return false
+}
+
+type paretoHeap []*paretoLabel
+
+func (h paretoHeap) Len() int            { return len(h) }
+func (h paretoHeap) Less(i, j int) bool  { return h[i].before(h[j]) }
+func (h paretoHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *paretoHeap) Push(x interface{}) { *h = append(*h, x.(*paretoLabel)) }
+func (h *paretoHeap) Pop() interface{} {
+	old := *h
+	l := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return l
+}
+
+func anyDominates(labels []*paretoLabel, l *paretoLabel, maxJumps int) bool {
+	for _, o := range labels {
+		if o.dominates(l, maxJumps) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParetoRoutes finds the routes from the query's sources to its targets that
+// trade cost (jumps, unless the query adds penalties or bridge costs) against
+// the measures, so that each is better than every other on at least one of
+// them. Routes tied on everything are returned once. Prefer-not lists are
+// ignored, and budgets and the time objective are not supported. Limit caps the
+// number of routes returned, cheapest first.
+func (f *Finder) ParetoRoutes(ctx context.Context, q Query, measures []Measure) ([]ParetoRoute, error) {
+	if err := q.validate(f); err != nil {
+		return nil, err
+	}
+
+	if len(measures) == 0 {
+		return nil, errors.Wrap(ErrBadQuery, "no measures to trade off")
+	}
+
+	if q.Objective != Shortest || len(q.Budgets) > 0 || len(q.Priorities) > 0 {
+		return nil, errors.Wrap(ErrBadQuery, "pareto routes only work with the jumps objective, without budgets or priorities")
+	}
+
+	sets := make([]bitset.Set, len(measures))
+	for i, m := range measures {
+		switch {
+		case m.Scores != nil:
+		case m.Expr != nil:
+			sets[i] = f.exprSet(m.Expr)
+		default:
+			sets[i] = f.tagSet(m.Tag)
+		}
+	}
+
+	targets := f.targetSet(q)
+	blocked := f.blockedSet(q, f.filters(q.Avoids, q.AvoidTags, q.AvoidExprs))
+	net := f.network(q)
+
+	settled := make([][]*paretoLabel, len(f.graph))
+	var found []*paretoLabel
+
+	queue := &paretoHeap{}
+	for _, s := range q.Sources {
+		heap.Push(queue, &paretoLabel{system: s, measures: make([]float64, len(measures))})
+	}
+
+	numLabels, pops := 0, 0
+	for queue.Len() > 0 {
+		if pops%1024 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, errors.Wrap(err, "search aborted")
+			}
+		}
+		pops++
+
+		curr := heap.Pop(queue).(*paretoLabel)
+		if anyDominates(settled[curr.system], curr, q.MaxJumps) || anyDominates(found, curr, q.MaxJumps) {
+			continue
+		}
+
+		settled[curr.system] = append(settled[curr.system], curr)
+		if curr.pred != nil && targets.Has(curr.system) {
+			found = append(found, curr)
+			continue
+		}
+
+		if q.MaxJumps > 0 && curr.jumps >= q.MaxJumps {
+			continue
+		}
+
+		net.each(curr.system, nil, func(e Edge, w int) {
+			if blocked.Has(e.To) {
+				return
+			}
+
+			next := &paretoLabel{
+				system:   e.To,
+				cost:     curr.cost + w,
+				jumps:    curr.jumps + 1,
+				measures: make([]float64, len(measures)),
+				pred:     curr,
+				edge:     e,
+			}
+
+			for i, m := range measures {
+				next.measures[i] = curr.measures[i]
+				switch {
+				case m.Scores != nil:
+					next.measures[i] += m.Scores[e.To]
+				case sets[i].Has(e.To):
+					next.measures[i]++
+				}
+			}
+
+			if anyDominates(settled[e.To], next, q.MaxJumps) || anyDominates(found, next, q.MaxJumps) {
+				return
+			}
+
+			heap.Push(queue, next)
+			numLabels++
+		})
+
+		if numLabels > paretoMaxLabels {
+			return nil, errors.Wrap(ErrSearchTooLarge, "too many trade-offs to search; try setting max jumps", "labels", numLabels)
+		}
+	}
+
+	if len(found) == 0 {
+		return nil, errors.Wrap(ErrNoRoute, "could not find route")
+	}
+
+	sort.SliceStable(found, func(i, j int) bool { return found[i].before(found[j]) })
+	if q.Limit > 0 && len(found) > q.Limit {
+		found = found[:q.Limit]
+	}
+
+	routes := make([]ParetoRoute, len(found))
+	for i, end := range found {
+		var c candidate
+		for l := end; l != nil; l = l.pred {
+			c.systems = append([]int{l.system}, c.systems...)
+			if l.pred != nil {
+				c.edges = append([]Edge{l.edge}, c.edges...)
+			}
+		}
+		c.cost = end.cost
+
+		routes[i] = ParetoRoute{
+			Route:    f.newRoute(c, nil, nil),
+			Measures: end.measures,
+		}
+	}
+
+	return f.addParetoETAs(routes, q.travel()), nil
+}
+
+func (f *Finder) addParetoETAs(routes []ParetoRoute, t Travel) []ParetoRoute {
+	for i := range routes {
+		routes[i].Route.ETA, routes[i].Route.WarpAU = f.eta(routes[i].Route.Edges, t)
+	}
+
+	return routes
+}
@@ -0,0 +1,47 @@
+package path
+
+import (
+	"context"
+	stderr "errors"
+	"reflect"
+	"testing"
+)
+
+// TestParetoMaxJumps checks a cheaper partial route that is too long to finish
+// within max jumps does not crowd out a shorter one.
+func TestParetoMaxJumps(t *testing.T) {
+	f := newTestFinder()
+
+	// the penalties make A-F-G-H the cheapest way to D, but it leaves only
+	// two jumps of the four to reach J
+	routes, err := f.ParetoRoutes(context.Background(), Query{
+		Sources:   []int{sysA},
+		Targets:   []int{sysJ},
+		Penalties: map[int]int{sysB: 3, sysC: 3},
+		MaxJumps:  4,
+	}, []Measure{{Name: "low", Tag: tagLow}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got [][]int
+	for _, r := range routes {
+		got = append(got, r.Route.Systems)
+	}
+
+	if want := [][]int{{sysA, sysC, sysD, sysI, sysJ}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestParetoCanceled(t *testing.T) {
+	f := newTestFinder()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := f.ParetoRoutes(ctx, Query{Sources: []int{sysA}, Targets: []int{sysJ}}, []Measure{{Name: "low", Tag: tagLow}})
+	if !stderr.Is(err, context.Canceled) {
+		t.Errorf("got %v; want context.Canceled", err)
+	}
+}
@@ -0,0 +1,38 @@
+package path
+
+import (
+	"github.com/gsmcwhirter/eve-route-finder/pkg/bitset"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/tagexpr"
+)
+
+// Priority is one criterion of a lexicographic route objective: the fewest
+// jumps into systems carrying Tag, or matching Expr if it is set. With
+// Maximize, it is the most jumps into those systems, counted as the fewest
+// jumps into any others.
+type Priority struct {
+	Tag      int
+	Expr     tagexpr.Expr
+	Maximize bool
+}
+
+func (f *Finder) prioritySet(p Priority) bitset.Set {
+	var systems bitset.Set
+	if p.Expr != nil {
+		systems = f.exprSet(p.Expr)
+	} else {
+		systems = f.tagSet(p.Tag)
+	}
+
+	if !p.Maximize {
+		return systems
+	}
+
+	others := bitset.New(len(f.graph))
+	for sys := range f.graph {
+		if !systems.Has(sys) {
+			others.Add(sys)
+		}
+	}
+
+	return others
+}
@@ -0,0 +1,374 @@
+package path
+
+import (
+	"context"
+	stderr "errors"
+
+	"github.com/gonum/stat/combin"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/bitset"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/tagexpr"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+)
+
+// Query describes a route search. A system is a target if it is listed in
+// Targets, carries any of TargetTags, or matches any of TargetExprs. The
+// prefer-not tags and expressions are avoided unless no route exists otherwise,
+// in which case as few of them as possible are dropped.
+type Query struct {
+	Sources     []int
+	Targets     []int
+	TargetTags  []int
+	TargetExprs []tagexpr.Expr
+
+	Avoids         []int
+	AvoidGates     []int64 // stargates not to jump through or arrive at
+	AvoidTags      []int
+	AvoidExprs     []tagexpr.Expr
+	PreferNotTags  []int
+	PreferNotExprs []tagexpr.Expr
+
+	MaxJumps int // 0 means unlimited
+	Budgets  []Budget
+	Limit    int // maximum number of routes returned; 0 means all of them
+
+	// jump bridges are only used when asked for, optionally only those of some
+	// owners. Each bridge jump costs BridgeCost stargate jumps (1 if unset).
+	UseBridges   bool
+	BridgeOwners []string
+	BridgeCost   int
+
+	// Wormholes are temporary one-way edges searched along with the stargates,
+	// each costing one jump. Callers pass only the ones still open.
+	Wormholes []Edge
+
+	// Penalties adds extra cost to every jump into a system, steering routes
+	// away from it without ruling it out.
+	Penalties map[int]int
+
+	// Priorities ranks routes lexicographically, by the first priority, then
+	// the next, and finally by jumps and penalties. Only routes tied on all of
+	// them are returned. Priorities need the Shortest objective.
+	Priorities []Priority
+
+	// Objective picks between fewest jumps and least travel time, which is
+	// estimated with Travel (DefaultTravel if unset). When going for time,
+	// penalties and bridge costs are counted as that many typical gate hops.
+	Objective RouteObjective
+	Travel    *Travel
+}
+
+func (q *Query) travel() Travel {
+	if q.Travel == nil {
+		return DefaultTravel
+	}
+
+	return *q.Travel
+}
+
+// Budget caps the number of jumps a route may make into systems carrying Tag.
+// Sec classes are tags, so this covers limits like "at most 3 low-sec jumps".
+type Budget struct {
+	Tag int
+	Max int
+}
+
+type budget struct {
+	systems bitset.Set
+	max     int
+}
+
+func (f *Finder) budgets(q Query) []budget {
+	budgets := make([]budget, len(q.Budgets))
+	for i, b := range q.Budgets {
+		budgets[i] = budget{
+			systems: f.tagSet(b.Tag),
+			max:     b.Max,
+		}
+	}
+
+	return budgets
+}
+
+var (
+	ErrBadQuery = errors.New("bad query")
+
+	// ErrNoCoordinates is returned for searches that need positions the
+	// system data file was generated without.
+	ErrNoCoordinates = errors.New("system data lacks coordinates")
+)
+
+func (q *Query) validate(f *Finder) error {
+	if len(q.Sources) == 0 {
+		return errors.Wrap(ErrBadQuery, "no source systems")
+	}
+
+	if len(q.Targets) == 0 && len(q.TargetTags) == 0 && len(q.TargetExprs) == 0 {
+		return errors.Wrap(ErrBadQuery, "no target systems, tags, or expressions")
+	}
+
+	for _, s := range q.Sources {
+		if s < 0 || s >= len(f.graph) {
+			return errors.Wrap(ErrBadQuery, "unknown source system", "system", s)
+		}
+	}
+
+	for _, t := range q.Targets {
+		if t < 0 || t >= len(f.graph) {
+			return errors.Wrap(ErrBadQuery, "unknown target system", "system", t)
+		}
+	}
+
+	if q.MaxJumps < 0 {
+		return errors.Wrap(ErrBadQuery, "negative max jumps")
+	}
+
+	if t := q.Travel; t != nil && (t.WarpSpeed <= 0 || t.AlignTime < 0 || t.JumpTime < 0 || t.GateCloak < 0 || t.FallbackWarpAU < 0) {
+		return errors.Wrap(ErrBadQuery, "bad travel parameters")
+	}
+
+	for _, g := range q.AvoidGates {
+		if g == 0 {
+			return errors.Wrap(ErrBadQuery, "unknown gate", "gate", g)
+		}
+	}
+
+	for sys, p := range q.Penalties {
+		if p < 0 {
+			return errors.Wrap(ErrBadQuery, "negative penalty", "system", sys)
+		}
+	}
+
+	for _, b := range q.Budgets {
+		if b.Max < 0 {
+			return errors.Wrap(ErrBadQuery, "negative budget", "tag", b.Tag)
+		}
+	}
+
+	if q.Objective == Fastest && !f.HasGatePositions() {
+		return errors.Wrap(ErrNoCoordinates, "the time objective needs stargate positions")
+	}
+
+	if len(q.Priorities) > 0 && q.Objective != Shortest {
+		return errors.Wrap(ErrBadQuery, "priorities only work with the jumps objective")
+	}
+
+	return nil
+}
+
+// filter is a single avoid or prefer-not constraint, along with the set of
+// systems it covers. Exactly one of system, tag, and expr is set.
+type filter struct {
+	system  int
+	tag     int
+	expr    tagexpr.Expr
+	systems bitset.Set
+}
+
+func (f *Finder) filters(avoids, tags []int, exprs []tagexpr.Expr) []filter {
+	filters := make([]filter, 0, len(avoids)+len(tags)+len(exprs))
+
+	for _, a := range avoids {
+		systems := bitset.New(len(f.graph))
+		if a >= 0 && a < len(f.graph) {
+			systems.Add(a)
+		}
+		filters = append(filters, filter{system: a, tag: -1, systems: systems})
+	}
+
+	for _, t := range tags {
+		filters = append(filters, filter{system: -1, tag: t, systems: f.tagSet(t)})
+	}
+
+	for _, e := range exprs {
+		filters = append(filters, filter{system: -1, tag: -1, expr: e, systems: f.exprSet(e)})
+	}
+
+	return filters
+}
+
+// Route finds the shortest routes from any of the query's sources to any of its
+// targets. When there are several sources, only the routes from the sources
+// closest to a target are returned.
+func (f *Finder) Route(ctx context.Context, q Query) ([]Route, error) {
+	if err := q.validate(f); err != nil {
+		return nil, err
+	}
+
+	return f.route(ctx, q, f.targetSet(q))
+}
+
+// route finds the best-ranked routes to targets, dropping prefer-not filters
+// if it has to.
+func (f *Finder) route(ctx context.Context, q Query, targets bitset.Set) ([]Route, error) {
+	if err := startsAtTarget(q, targets); err != nil {
+		return nil, err
+	}
+
+	hard := f.filters(q.Avoids, q.AvoidTags, q.AvoidExprs)
+	soft := f.filters(nil, q.PreferNotTags, q.PreferNotExprs)
+
+	routes, err := f.findAllShortestRoutes(ctx, q, targets, f.blockedSet(q, hard, soft))
+	if err == nil {
+		return f.addETAs(f.newRoutes(routes, soft, nil), q.travel()), nil
+	}
+
+	if !stderr.Is(err, ErrNoRoute) {
+		return nil, err
+	}
+
+	numPrefer := len(soft)
+	for i := 1; i <= numPrefer; i++ { //omit this many prefer-not filters to try and find a route
+		var looserRoutes []Route
+		var best *rank
+
+		for _, combo := range combin.Combinations(numPrefer, numPrefer-i) {
+			keptFilters := make([]filter, 0, numPrefer-i)
+			kept := make([]bool, numPrefer)
+			for _, idx := range combo {
+				keptFilters = append(keptFilters, soft[idx])
+				kept[idx] = true
+			}
+
+			routes, err := f.findAllShortestRoutes(ctx, q, targets, f.blockedSet(q, hard, keptFilters))
+			if stderr.Is(err, ErrNoRoute) {
+				continue
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			relaxed := make([]filter, 0, i)
+			for idx, sf := range soft {
+				if !kept[idx] {
+					relaxed = append(relaxed, sf)
+				}
+			}
+
+			switch r := routes[0].rank; {
+			case best == nil || r.compare(*best) < 0:
+				best = &r
+				looserRoutes = f.newRoutes(routes, soft, relaxed)
+			case r.compare(*best) == 0:
+				looserRoutes = append(looserRoutes, f.newRoutes(routes, soft, relaxed)...)
+			}
+		}
+
+		if len(looserRoutes) == 0 {
+			continue
+		}
+
+		if q.Limit > 0 && len(looserRoutes) > q.Limit {
+			looserRoutes = looserRoutes[:q.Limit]
+		}
+
+		return f.addETAs(looserRoutes, q.travel()), nil
+	}
+
+	cut, err := f.findCut(ctx, q, targets, hard)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, &NoRouteError{
+		Cut:   cut,
+		cause: errors.Wrap(ErrNoRoute, "could not find route"),
+	}
+}
+
+// startsAtTarget fails when every source is already a target, since no
+// relaxing or lifting of filters can give those a route.
+func startsAtTarget(q Query, targets bitset.Set) error {
+	for _, s := range q.Sources {
+		if !targets.Has(s) {
+			return nil
+		}
+	}
+
+	if len(q.TargetTags) == 0 && len(q.TargetExprs) == 0 {
+		return errors.Wrap(ErrNoRoute, "start and end are identical")
+	}
+
+	return errors.Wrap(ErrNoRoute, "start has the tag")
+}
+
+func (f *Finder) findAllShortestRoutes(ctx context.Context, q Query, targets, blocked bitset.Set) ([]candidate, error) {
+	var minRoutes []candidate
+	var best *rank
+
+	s := &search{
+		targets:  targets,
+		blocked:  blocked,
+		budgets:  f.budgets(q),
+		net:      f.network(q),
+		maxJumps: q.MaxJumps,
+		limit:    q.Limit,
+	}
+
+	for _, p := range q.Priorities {
+		s.priorities = append(s.priorities, f.prioritySet(p))
+	}
+
+	for _, start := range q.Sources {
+		routes, err := f.findShortestRoutes(ctx, start, s)
+		if stderr.Is(err, ErrNoRoute) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch r := routes[0].rank; {
+		case best == nil || r.compare(*best) < 0:
+			best = &r
+			minRoutes = routes
+		case r.compare(*best) == 0:
+			minRoutes = append(minRoutes, routes...)
+		}
+	}
+
+	if best == nil {
+		return nil, errors.Wrap(ErrNoRoute, "could not find route")
+	}
+
+	if q.Limit > 0 && len(minRoutes) > q.Limit {
+		minRoutes = minRoutes[:q.Limit]
+	}
+
+	return minRoutes, nil
+}
+
+func (f *Finder) targetSet(q Query) bitset.Set {
+	targets := bitset.New(len(f.graph))
+	for _, t := range q.Targets {
+		targets.Add(t)
+	}
+
+	for _, t := range q.TargetTags {
+		targets.Union(f.tagSet(t))
+	}
+
+	for _, e := range q.TargetExprs {
+		targets.Union(f.exprSet(e))
+	}
+
+	return targets
+}
+
+// blockedSet marks the systems a search may not pass through. Systems named
+// explicitly as targets are never blocked.
+func (f *Finder) blockedSet(q Query, filterLists ...[]filter) bitset.Set {
+	blocked := bitset.New(len(f.graph))
+	for _, filters := range filterLists {
+		for _, fl := range filters {
+			blocked.Union(fl.systems)
+		}
+	}
+
+	for _, t := range q.Targets {
+		blocked.Remove(t)
+	}
+
+	return blocked
+}
@@ -0,0 +1,305 @@
+package path
+
+import (
+	"context"
+	stderr "errors"
+	"reflect"
+	"testing"
+)
+
+// TestLegacyWrappers checks the old entry points give the same results as the
+// breadth-first search they replaced.
+func TestLegacyWrappers(t *testing.T) {
+	f := newTestFinder()
+
+	tests := []struct {
+		name string
+		find func() ([][]int, error)
+		want [][]int
+	}{
+		{
+			name: "both shortest routes",
+			find: func() ([][]int, error) { return f.FindShortestRoutes(sysA, sysD, nil, nil, nil) },
+			want: [][]int{{sysA, sysB, sysD}, {sysA, sysC, sysD}},
+		},
+		{
+			name: "prefer not low",
+			find: func() ([][]int, error) { return f.FindShortestRoutes(sysA, sysD, nil, nil, []int{tagLow}) },
+			want: [][]int{{sysA, sysC, sysD}},
+		},
+		{
+			name: "avoid null",
+			find: func() ([][]int, error) { return f.FindShortestRoutes(sysA, sysJ, nil, []int{tagNull}, nil) },
+			want: [][]int{{sysA, sysB, sysD, sysI, sysJ}},
+		},
+		{
+			name: "start and end identical",
+			find: func() ([][]int, error) { return f.FindShortestRoutes(sysA, sysA, nil, nil, nil) },
+		},
+		{
+			name: "closest start",
+			find: func() ([][]int, error) { return f.FindAllShortestRoutes([]int{sysA, sysE}, sysD, nil, nil, nil) },
+			want: [][]int{{sysE, sysD}},
+		},
+		{
+			name: "to tag",
+			find: func() ([][]int, error) { return f.FindShortestRoutesToTag(sysA, tagLow, nil, nil, nil) },
+			want: [][]int{{sysA, sysB}},
+		},
+		{
+			name: "start has the tag",
+			find: func() ([][]int, error) { return f.FindShortestRoutesToTag(sysA, tagHigh, nil, nil, nil) },
+		},
+		{
+			name: "to tag from several starts",
+			find: func() ([][]int, error) { return f.FindAllShortestRoutesToTag([]int{sysA, sysJ}, tagLow, nil, nil, nil) },
+			want: [][]int{{sysA, sysB}, {sysJ, sysI}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.find()
+			if tt.want == nil {
+				if !stderr.Is(err, ErrNoRoute) {
+					t.Fatalf("got %v, %v; want ErrNoRoute", got, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoute(t *testing.T) {
+	f := newTestFinder()
+
+	tests := []struct {
+		name        string
+		q           Query
+		want        [][]int
+		wantRelaxed [][]int
+	}{
+		{
+			name: "prefer not kept when possible",
+			q:    Query{Sources: []int{sysA}, Targets: []int{sysD}, PreferNotTags: []int{tagLow, tagNull}},
+			want: [][]int{{sysA, sysF, sysG, sysH, sysD}},
+		},
+		{
+			name:        "prefer not relaxed",
+			q:           Query{Sources: []int{sysA}, Targets: []int{sysD}, Avoids: []int{sysG}, PreferNotTags: []int{tagLow, tagNull}},
+			want:        [][]int{{sysA, sysC, sysD}, {sysA, sysB, sysD}},
+			wantRelaxed: [][]int{{tagNull}, {tagLow}},
+		},
+		{
+			// the route through B reaches D cheaper but spends the low-sec
+			// budget that I needs, so the longer high-sec route has to survive
+			name: "budget keeps a costlier label",
+			q: Query{
+				Sources: []int{sysA},
+				Targets: []int{sysJ},
+				Budgets: []Budget{{Tag: tagLow, Max: 1}, {Tag: tagNull, Max: 0}},
+			},
+			want: [][]int{{sysA, sysF, sysG, sysH, sysD, sysI, sysJ}},
+		},
+		{
+			name: "max jumps",
+			q:    Query{Sources: []int{sysA}, Targets: []int{sysD}, AvoidTags: []int{tagLow}, MaxJumps: 2},
+			want: [][]int{{sysA, sysC, sysD}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes, err := f.Route(context.Background(), tt.q)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := routeSystems(routes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v; want %v", got, tt.want)
+			}
+
+			if tt.wantRelaxed == nil {
+				return
+			}
+
+			for i, r := range routes {
+				if !reflect.DeepEqual(r.Relaxed, tt.wantRelaxed[i]) {
+					t.Errorf("route %d relaxed %v; want %v", i, r.Relaxed, tt.wantRelaxed[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRouteNoRouteCut(t *testing.T) {
+	f := newTestFinder()
+
+	_, err := f.Route(context.Background(), Query{
+		Sources:   []int{sysA},
+		Targets:   []int{sysD},
+		Avoids:    []int{sysB, sysG},
+		AvoidTags: []int{tagNull},
+	})
+
+	var noRoute *NoRouteError
+	if !stderr.As(err, &noRoute) {
+		t.Fatalf("got %v; want a NoRouteError", err)
+	}
+
+	if !stderr.Is(err, ErrNoRoute) {
+		t.Errorf("%v does not wrap ErrNoRoute", err)
+	}
+
+	want := &Cut{Avoids: []int{sysB}, Jumps: 2}
+	if !reflect.DeepEqual(noRoute.Cut, want) {
+		t.Errorf("got cut %+v; want %+v", noRoute.Cut, want)
+	}
+}
+
+func TestRouteCanceled(t *testing.T) {
+	f := newTestFinder()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := f.Route(ctx, Query{Sources: []int{sysA}, Targets: []int{sysJ}})
+	if !stderr.Is(err, context.Canceled) {
+		t.Errorf("got %v; want context.Canceled", err)
+	}
+}
+
+// TestNearestRoutes checks targets are picked and listed by jumps first, so a
+// penalized target comes before a farther one that is cheaper to reach.
+func TestNearestRoutes(t *testing.T) {
+	f := newTestFinder()
+
+	tests := []struct {
+		name string
+		q    Query
+		want [][]int
+	}{
+		{
+			name: "penalized target first",
+			q:    Query{Sources: []int{sysA}, TargetTags: []int{tagLow}, Penalties: map[int]int{sysB: 5}, Limit: 2},
+			want: [][]int{{sysA, sysB}, {sysA, sysC, sysD, sysI}},
+		},
+		{
+			name: "cheapest target not nearest",
+			q:    Query{Sources: []int{sysA}, Targets: []int{sysB, sysE, sysJ}, Penalties: map[int]int{sysB: 10}, Limit: 2},
+			want: [][]int{{sysA, sysB}, {sysA, sysC, sysD, sysE}},
+		},
+		{
+			name: "through a nearer target",
+			q:    Query{Sources: []int{sysA}, Targets: []int{sysD, sysJ}, Avoids: []int{sysB}, Limit: 3},
+			want: [][]int{{sysA, sysC, sysD}, {sysA, sysC, sysD, sysI, sysJ}},
+		},
+		{
+			name: "several sources",
+			q:    Query{Sources: []int{sysA, sysJ}, Targets: []int{sysB, sysD, sysI}, Penalties: map[int]int{sysB: 1}, Limit: 2},
+			want: [][]int{{sysJ, sysI}, {sysA, sysB}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes, err := f.NearestRoutes(context.Background(), tt.q)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := routeSystems(routes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoutePriorities(t *testing.T) {
+	f := newTestFinder()
+
+	low := Priority{Tag: tagLow}
+	null := Priority{Tag: tagNull}
+
+	tests := []struct {
+		name       string
+		q          Query
+		want       [][]int
+		wantCost   int
+		wantCounts []int
+	}{
+		{
+			name:       "low before null",
+			q:          Query{Sources: []int{sysA}, Targets: []int{sysD}, Avoids: []int{sysG}, Priorities: []Priority{low, null}},
+			want:       [][]int{{sysA, sysC, sysD}},
+			wantCost:   2,
+			wantCounts: []int{0, 1},
+		},
+		{
+			name:       "null before low",
+			q:          Query{Sources: []int{sysA}, Targets: []int{sysD}, Avoids: []int{sysG}, Priorities: []Priority{null, low}},
+			want:       [][]int{{sysA, sysB, sysD}},
+			wantCost:   2,
+			wantCounts: []int{0, 1},
+		},
+		{
+			name:       "priorities before jumps",
+			q:          Query{Sources: []int{sysA}, Targets: []int{sysD}, Priorities: []Priority{low, null}},
+			want:       [][]int{{sysA, sysF, sysG, sysH, sysD}},
+			wantCost:   4,
+			wantCounts: []int{0, 0},
+		},
+		{
+			name:       "jumps before penalties",
+			q:          Query{Sources: []int{sysA}, Targets: []int{sysD}, Penalties: map[int]int{sysB: 1}, Priorities: []Priority{{Tag: tagHigh}}},
+			want:       [][]int{{sysA, sysC, sysD}},
+			wantCost:   2,
+			wantCounts: []int{1},
+		},
+		{
+			name:       "ties kept",
+			q:          Query{Sources: []int{sysA}, Targets: []int{sysD}, Priorities: []Priority{{Tag: tagHigh}}},
+			want:       [][]int{{sysA, sysB, sysD}, {sysA, sysC, sysD}},
+			wantCost:   2,
+			wantCounts: []int{1},
+		},
+		{
+			name:       "maximize",
+			q:          Query{Sources: []int{sysA}, Targets: []int{sysD}, Priorities: []Priority{{Tag: tagHigh, Maximize: true}}},
+			want:       [][]int{{sysA, sysF, sysG, sysH, sysD}},
+			wantCost:   4,
+			wantCounts: []int{0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes, err := f.Route(context.Background(), tt.q)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := routeSystems(routes); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v; want %v", got, tt.want)
+			}
+
+			for _, r := range routes {
+				if r.Cost != tt.wantCost {
+					t.Errorf("route %v cost %d; want %d", r.Systems, r.Cost, tt.wantCost)
+				}
+
+				if !reflect.DeepEqual(r.PriorityCounts, tt.wantCounts) {
+					t.Errorf("route %v counts %v; want %v", r.Systems, r.PriorityCounts, tt.wantCounts)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package path
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/gsmcwhirter/go-util/v7/errors"
+)
+
+type RendezvousObjective int
+
+const (
+	MinMaxJumps   RendezvousObjective = iota // nobody travels too far
+	MinTotalJumps                            // least travel overall
+)
+
+func ParseRendezvousObjective(s string) (RendezvousObjective, error) {
+	switch s {
+	case "", "max":
+		return MinMaxJumps, nil
+	case "total":
+		return MinTotalJumps, nil
+	default:
+		return MinMaxJumps, errors.Wrap(ErrBadQuery, "unknown rendezvous objective", "objective", s)
+	}
+}
+
+// Member is a fleet member's location. Members with a larger weight count for
+// more, e.g. a slow ship or a group of several pilots; a zero weight counts
+// as 1.
+type Member struct {
+	System int
+	Weight float64
+}
+
+// RendezvousQuery asks for the systems where the members can meet with the
+// least (weighted) maximum or total travel. Limit caps the number of systems
+// returned, 5 if unset.
+type RendezvousQuery struct {
+	Members []Member
+	Constraints
+	Objective RendezvousObjective
+	Limit     int
+}
+
+// Rendezvous is a meeting system, with each member's route to it in the order
+// the members were given.
+type Rendezvous struct {
+	System      int
+	MaxJumps    int
+	TotalJumps  int
+	WeightedMax float64
+	WeightedSum float64
+	Routes      []Route
+}
+
+const defaultRendezvousLimit = 5
+
+func (q *RendezvousQuery) validate(numSystems int) error {
+	if len(q.Members) == 0 {
+		return errors.Wrap(ErrBadQuery, "no fleet members")
+	}
+
+	for _, m := range q.Members {
+		if m.System < 0 || m.System >= numSystems {
+			return errors.Wrap(ErrBadQuery, "unknown member system", "system", m.System)
+		}
+
+		if m.Weight < 0 {
+			return errors.Wrap(ErrBadQuery, "negative member weight", "system", m.System)
+		}
+	}
+
+	if q.Limit < 0 {
+		return errors.Wrap(ErrBadQuery, "negative limit")
+	}
+
+	return nil
+}
+
+// FindRendezvous ranks the systems every member can reach by the query's
+// objective, breaking ties by the other objective and then by fewest total
+// jumps.
+func (f *Finder) FindRendezvous(ctx context.Context, q RendezvousQuery) ([]Rendezvous, error) {
+	if err := q.validate(len(f.graph)); err != nil {
+		return nil, err
+	}
+
+	limit := q.Limit
+	if limit == 0 {
+		limit = defaultRendezvousLimit
+	}
+
+	net, blocked := f.constrained(q.Constraints)
+
+	maps := make([]*jumpMap, len(q.Members))
+	for i, m := range q.Members {
+		var err error
+		if maps[i], err = f.jumpsFrom(ctx, m.System, net, blocked, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	var found []Rendezvous
+	for sys := range f.graph {
+		if blocked.Has(sys) {
+			continue
+		}
+
+		r := Rendezvous{System: sys}
+		reachable := true
+		for i, m := range q.Members {
+			d := maps[i].dist[sys]
+			if d == -1 {
+				reachable = false
+				break
+			}
+
+			w := m.Weight
+			if w == 0 {
+				w = 1
+			}
+
+			if d > r.MaxJumps {
+				r.MaxJumps = d
+			}
+			r.TotalJumps += d
+			r.WeightedMax = math.Max(r.WeightedMax, w*float64(d))
+			r.WeightedSum += w * float64(d)
+		}
+
+		if reachable {
+			found = append(found, r)
+		}
+	}
+
+	if len(found) == 0 {
+		return nil, errors.Wrap(ErrNoRoute, "no system every member can reach")
+	}
+
+	primary := func(r Rendezvous) (float64, float64) {
+		if q.Objective == MinTotalJumps {
+			return r.WeightedSum, r.WeightedMax
+		}
+		return r.WeightedMax, r.WeightedSum
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		pi, si := primary(found[i])
+		pj, sj := primary(found[j])
+		switch {
+		case pi != pj:
+			return pi < pj
+		case si != sj:
+			return si < sj
+		case found[i].TotalJumps != found[j].TotalJumps:
+			return found[i].TotalJumps < found[j].TotalJumps
+		default:
+			return found[i].System < found[j].System
+		}
+	})
+
+	if len(found) > limit {
+		found = found[:limit]
+	}
+
+	travel := q.travel()
+	for i := range found {
+		found[i].Routes = make([]Route, len(q.Members))
+		for j := range q.Members {
+			c, _ := maps[j].path(found[i].System)
+			found[i].Routes[j] = f.newRoute(c, nil, nil)
+			found[i].Routes[j].ETA, found[i].Routes[j].WarpAU = f.eta(c.edges, travel)
+		}
+	}
+
+	return found, nil
+}
@@ -0,0 +1,306 @@
+package path
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/bitset"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/tagexpr"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+)
+
+// RoamQuery asks for loops out of Home and back that pass through at least
+// Systems other systems without visiting any twice, in at most MaxJumps jumps
+// (Systems+1 if unset). Loops through more systems carrying the preferred tags
+// or matching the preferred expressions rank higher. Limit caps the number of
+// loops returned, 3 if unset. The search is randomized; Seed makes it
+// repeatable, and a zero seed uses the current time.
+type RoamQuery struct {
+	Home        int
+	Systems     int
+	MaxJumps    int
+	PreferTags  []int
+	PreferExprs []tagexpr.Expr
+	Constraints
+	Limit int
+	Seed  int64
+}
+
+// Roam is a loop starting and ending at home. Preferred counts the systems in
+// the loop carrying a preferred tag or matching a preferred expression.
+type Roam struct {
+	Route     Route
+	Preferred int
+}
+
+const (
+	defaultRoamLimit = 3
+	roamRestarts     = 25
+	roamExpansions   = 200000 // across all restarts
+
+	// loops sharing more than this fraction of their systems with a better
+	// loop are only returned if there are not enough others
+	roamOverlap = 0.5
+)
+
+func (q *RoamQuery) validate(numSystems int) error {
+	if q.Home < 0 || q.Home >= numSystems {
+		return errors.Wrap(ErrBadQuery, "unknown home system", "system", q.Home)
+	}
+
+	if q.Systems < 2 {
+		return errors.Wrap(ErrBadQuery, "a roam must visit at least two systems")
+	}
+
+	if q.MaxJumps != 0 && q.MaxJumps < q.Systems+1 {
+		return errors.Wrap(ErrBadQuery, "max jumps too small to visit every system", "max_jumps", q.MaxJumps, "systems", q.Systems)
+	}
+
+	if q.Limit < 0 {
+		return errors.Wrap(ErrBadQuery, "negative limit")
+	}
+
+	return nil
+}
+
+// roamSearch is a randomized depth-first search for loops back to home. It
+// assumes jumps work both ways when pruning by the distance back home.
+type roamSearch struct {
+	net      *network
+	blocked  bitset.Set
+	prefer   bitset.Set
+	home     int
+	dist     []int // jumps from home
+	minSys   int
+	maxJumps int
+	rng      *rand.Rand
+
+	onPath  bitset.Set
+	systems []int
+	edges   []Edge
+	budget  int
+
+	seen  map[string]bool
+	found []candidate
+}
+
+func (s *roamSearch) walk(ctx context.Context) error {
+	if s.budget <= 0 {
+		return nil
+	}
+	s.budget--
+
+	if s.budget%1024 == 0 {
+		if err := ctx.Err(); err != nil {
+			return errors.Wrap(err, "search aborted")
+		}
+	}
+
+	cur := s.systems[len(s.systems)-1]
+	jumps := len(s.edges)
+
+	var next []Edge
+	s.net.each(cur, nil, func(e Edge, _ int) {
+		if e.To == s.home {
+			if len(s.systems)-1 >= s.minSys {
+				s.record(e)
+			}
+			return
+		}
+
+		if s.onPath.Has(e.To) || s.blocked.Has(e.To) {
+			return
+		}
+
+		if d := s.dist[e.To]; d == -1 || jumps+1+d > s.maxJumps {
+			return
+		}
+
+		next = append(next, e)
+	})
+
+	s.rng.Shuffle(len(next), func(i, j int) { next[i], next[j] = next[j], next[i] })
+	sort.SliceStable(next, func(i, j int) bool {
+		return s.prefer.Has(next[i].To) && !s.prefer.Has(next[j].To)
+	})
+
+	for _, e := range next {
+		s.onPath.Add(e.To)
+		s.systems = append(s.systems, e.To)
+		s.edges = append(s.edges, e)
+
+		err := s.walk(ctx)
+
+		s.onPath.Remove(e.To)
+		s.systems = s.systems[:len(s.systems)-1]
+		s.edges = s.edges[:len(s.edges)-1]
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// record keeps the current path closed by e, once per set of systems.
+func (s *roamSearch) record(e Edge) {
+	members := append([]int(nil), s.systems[1:]...)
+	sort.Ints(members)
+	key := fmt.Sprint(members)
+	if s.seen[key] {
+		return
+	}
+	s.seen[key] = true
+
+	c := candidate{
+		systems: append(append([]int(nil), s.systems...), s.home),
+		edges:   append(append([]Edge(nil), s.edges...), e),
+	}
+	c.cost = len(c.edges)
+	s.found = append(s.found, c)
+}
+
+// FindRoams looks for loops out of home and back, returning the ones through
+// the most preferred systems and then the fewest jumps, while trying to keep
+// them different from each other.
+func (f *Finder) FindRoams(ctx context.Context, q RoamQuery) ([]Roam, error) {
+	if err := q.validate(len(f.graph)); err != nil {
+		return nil, err
+	}
+
+	limit := q.Limit
+	if limit == 0 {
+		limit = defaultRoamLimit
+	}
+
+	maxJumps := q.MaxJumps
+	if maxJumps == 0 {
+		maxJumps = q.Systems + 1
+	}
+
+	seed := q.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	net, blocked := f.constrained(q.Constraints, q.Home)
+
+	m, err := f.jumpsFrom(ctx, q.Home, net, blocked, maxJumps)
+	if err != nil {
+		return nil, err
+	}
+
+	prefer := bitset.New(len(f.graph))
+	for _, t := range q.PreferTags {
+		prefer.Union(f.tagSet(t))
+	}
+	for _, e := range q.PreferExprs {
+		prefer.Union(f.exprSet(e))
+	}
+
+	s := &roamSearch{
+		net:      net,
+		blocked:  blocked,
+		prefer:   prefer,
+		home:     q.Home,
+		dist:     m.dist,
+		minSys:   q.Systems,
+		maxJumps: maxJumps,
+		rng:      rand.New(rand.NewSource(seed)),
+		onPath:   bitset.New(len(f.graph)),
+		seen:     map[string]bool{},
+	}
+	s.onPath.Add(q.Home)
+
+	for i := 0; i < roamRestarts; i++ {
+		s.systems = []int{q.Home}
+		s.edges = nil
+		s.budget = roamExpansions / roamRestarts
+
+		if err := s.walk(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(s.found) == 0 {
+		return nil, errors.Wrap(ErrNoRoute, "no loop back home", "systems", q.Systems, "max_jumps", maxJumps)
+	}
+
+	roams := make([]Roam, 0, len(s.found))
+	for _, c := range s.found {
+		r := Roam{Route: f.newRoute(c, nil, nil)}
+		r.Route.ETA, r.Route.WarpAU = f.eta(c.edges, q.travel())
+		for _, sys := range c.systems[1 : len(c.systems)-1] {
+			if prefer.Has(sys) {
+				r.Preferred++
+			}
+		}
+		roams = append(roams, r)
+	}
+
+	sort.SliceStable(roams, func(i, j int) bool {
+		a, b := roams[i], roams[j]
+		if a.Preferred != b.Preferred {
+			return a.Preferred > b.Preferred
+		}
+		return a.Route.Jumps < b.Route.Jumps
+	})
+
+	return f.varied(roams, limit), nil
+}
+
+// varied picks up to limit roams in order, skipping any too much like one
+// already picked unless there are not enough others.
+func (f *Finder) varied(roams []Roam, limit int) []Roam {
+	var picked, skipped []Roam
+	sets := []bitset.Set{}
+
+	for _, r := range roams {
+		if len(picked) == limit {
+			break
+		}
+
+		set := bitset.New(len(f.graph))
+		for _, sys := range r.Route.Systems {
+			set.Add(sys)
+		}
+
+		similar := false
+		for _, o := range sets {
+			if overlap(set, o) > roamOverlap {
+				similar = true
+				break
+			}
+		}
+
+		if similar {
+			skipped = append(skipped, r)
+			continue
+		}
+
+		picked = append(picked, r)
+		sets = append(sets, set)
+	}
+
+	for _, r := range skipped {
+		if len(picked) == limit {
+			break
+		}
+		picked = append(picked, r)
+	}
+
+	return picked
+}
+
+// overlap is the Jaccard similarity of two sets of systems.
+func overlap(a, b bitset.Set) float64 {
+	both := a.Clone()
+	both.Union(b)
+
+	shared := a.Count() + b.Count() - both.Count()
+	return float64(shared) / float64(both.Count())
+}
@@ -0,0 +1,121 @@
+package path
+
+import (
+	"time"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/bitset"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/tagexpr"
+)
+
+// Route is a single route found by the Finder along with some statistics about
+// the systems it passes through. The source system is not counted in the
+// statistics, since the route never jumps into it.
+type Route struct {
+	Systems   []int
+	Edges     []Edge // the edge taken for each jump
+	Source    int
+	Jumps     int
+	Cost      int
+	ETA       time.Duration // estimated travel time
+	WarpAU    float64       // total in-system warp distance
+	SecCounts map[string]int
+	TagCounts map[int]int
+	Tags      []int
+
+	// prefer-not tags and expressions the route could not avoid
+	Violated      []int
+	ViolatedExprs []tagexpr.Expr
+
+	// prefer-not tags and expressions that were dropped from the search to find the route
+	Relaxed      []int
+	RelaxedExprs []tagexpr.Expr
+
+	// jumps counted by each of the query's priorities, in order; for a
+	// maximized priority, the jumps outside its systems
+	PriorityCounts []int
+}
+
+func (f *Finder) newRoute(c candidate, preferNot, relaxed []filter) Route {
+	systems := c.systems
+	r := Route{
+		Systems:   systems,
+		Edges:     c.edges,
+		Source:    systems[0],
+		Jumps:     len(systems) - 1,
+		Cost:      c.cost,
+		SecCounts: map[string]int{},
+		TagCounts: map[int]int{},
+
+		PriorityCounts: c.tiers,
+	}
+
+	visited := bitset.New(len(f.graph))
+	passed := bitset.New(len(f.tagSystems))
+
+	for _, sys := range systems[1:] {
+		visited.Add(sys)
+
+		if sys < len(f.secStatus) {
+			r.SecCounts[f.secStatus[sys]]++
+		}
+
+		for _, tag := range f.sysTags[sys].Members() {
+			r.TagCounts[tag]++
+		}
+		passed.Union(f.sysTags[sys])
+	}
+
+	r.Tags = passed.Members()
+
+	for _, fl := range preferNot {
+		if !fl.systems.Intersects(visited) {
+			continue
+		}
+
+		if fl.expr != nil {
+			r.ViolatedExprs = append(r.ViolatedExprs, fl.expr)
+		} else {
+			r.Violated = append(r.Violated, fl.tag)
+		}
+	}
+
+	for _, fl := range relaxed {
+		if fl.expr != nil {
+			r.RelaxedExprs = append(r.RelaxedExprs, fl.expr)
+		} else {
+			r.Relaxed = append(r.Relaxed, fl.tag)
+		}
+	}
+
+	return r
+}
+
+func (f *Finder) newRoutes(routes []candidate, preferNot, relaxed []filter) []Route {
+	ret := make([]Route, len(routes))
+	for i, c := range routes {
+		ret[i] = f.newRoute(c, preferNot, relaxed)
+	}
+
+	return ret
+}
+
+func (f *Finder) addETAs(routes []Route, t Travel) []Route {
+	for i := range routes {
+		routes[i].ETA, routes[i].WarpAU = f.eta(routes[i].Edges, t)
+	}
+
+	return routes
+}
+
+func routeSystems(routes []Route) [][]int {
+	if routes == nil {
+		return nil
+	}
+
+	ret := make([][]int, len(routes))
+	for i, r := range routes {
+		ret[i] = r.Systems
+	}
+
+	return ret
+}
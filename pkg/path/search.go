@@ -0,0 +1,452 @@
+package path
+
+import (
+	"container/heap"
+	"context"
+	"math"
+	"time"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/bitset"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+)
+
+// network is the set of edges a search may use, and what each one costs.
+// Stargates and wormholes cost 1, plus any penalty on the system jumped into.
+// When searching for the fastest route, costs are in seconds instead, and
+// depend on which edge the ship arrived by.
+type network struct {
+	f          *Finder
+	useBridges bool
+	owners     map[string]bool
+	bridgeCost int
+	wormholes  map[int][]Edge
+	penalties  map[int]int
+	avoidGates map[int64]bool
+
+	fastest bool
+	travel  Travel
+	unit    int // seconds in a typical hop, for penalties when fastest
+}
+
+func (f *Finder) network(q Query) *network {
+	n := &network{
+		f:          f,
+		useBridges: q.UseBridges,
+		bridgeCost: q.BridgeCost,
+		penalties:  q.Penalties,
+		fastest:    q.Objective == Fastest,
+		travel:     q.travel(),
+	}
+
+	if n.fastest {
+		via := Edge{}
+		n.unit = seconds(n.travel.hop(f, &via, Edge{}))
+	}
+
+	if n.bridgeCost <= 0 {
+		n.bridgeCost = 1
+	}
+
+	if len(q.AvoidGates) > 0 {
+		n.avoidGates = map[int64]bool{}
+		for _, g := range q.AvoidGates {
+			n.avoidGates[g] = true
+		}
+	}
+
+	for _, e := range q.Wormholes {
+		if e.From < 0 || e.From >= len(f.graph) || e.To < 0 || e.To >= len(f.graph) {
+			continue
+		}
+
+		if n.wormholes == nil {
+			n.wormholes = map[int][]Edge{}
+		}
+
+		e.Kind = Wormhole
+		n.wormholes[e.From] = append(n.wormholes[e.From], e)
+	}
+
+	if len(q.BridgeOwners) > 0 {
+		n.owners = map[string]bool{}
+		for _, o := range q.BridgeOwners {
+			n.owners[o] = true
+		}
+	}
+
+	return n
+}
+
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// cost is the cost of leaving a system through e, having arrived along via,
+// where e counts as the given number of jumps.
+func (n *network) cost(via *Edge, e Edge, jumps int) int {
+	if !n.fastest {
+		return jumps + n.penalties[e.To]
+	}
+
+	return seconds(n.travel.hop(n.f, via, e)) + (jumps-1+n.penalties[e.To])*n.unit
+}
+
+// each calls fn with every edge out of sys and its cost, having arrived along
+// via.
+func (n *network) each(sys int, via *Edge, fn func(e Edge, cost int)) {
+	for _, e := range n.f.gates[sys] {
+		if n.avoidGates[e.Gate] || n.avoidGates[e.ToGate] {
+			continue
+		}
+
+		fn(e, n.cost(via, e, 1))
+	}
+
+	for _, e := range n.wormholes[sys] {
+		fn(e, n.cost(via, e, 1))
+	}
+
+	if !n.useBridges {
+		return
+	}
+
+	for _, e := range n.f.bridges[sys] {
+		if n.owners != nil && !n.owners[e.Label] {
+			continue
+		}
+
+		fn(e, n.cost(via, e, n.bridgeCost))
+	}
+}
+
+// search holds everything about a query that stays the same from one source
+// system to the next.
+type search struct {
+	targets    bitset.Set
+	blocked    bitset.Set
+	budgets    []budget
+	priorities []bitset.Set
+	net        *network
+	maxJumps   int
+	limit      int
+}
+
+// rank is how a search orders its labels and routes: by the jumps counted by
+// each priority in turn, and then by cost. Without priorities it is just the
+// cost.
+type rank struct {
+	tiers []int
+	cost  int
+}
+
+// compare returns -1, 0, or 1 as r ranks ahead of, level with, or behind o.
+func (r rank) compare(o rank) int {
+	for i, t := range r.tiers {
+		switch {
+		case t < o.tiers[i]:
+			return -1
+		case t > o.tiers[i]:
+			return 1
+		}
+	}
+
+	switch {
+	case r.cost < o.cost:
+		return -1
+	case r.cost > o.cost:
+		return 1
+	}
+
+	return 0
+}
+
+// candidate is a route found by a search, before statistics are gathered.
+type candidate struct {
+	systems []int
+	edges   []Edge
+	rank
+}
+
+// label is a state in the route search: a system reached at some rank and
+// number of jumps, having used up some amount of each budget along the way.
+// preds holds every label that leads into this one at the same rank. via is
+// the edge the system was reached by, which only matters to the search when
+// costs depend on it.
+type label struct {
+	system int
+	rank
+	jumps int
+	usage []int
+	preds []link
+	via   *Edge
+}
+
+type link struct {
+	from *label
+	edge Edge
+}
+
+// dominates reports whether a route through l is never worse than one through
+// a label with the given rank, jumps, and usage, so the latter need not be
+// explored.
+func (l *label) dominates(r rank, jumps int, usage []int) bool {
+	if l.rank.compare(r) > 0 || l.jumps > jumps {
+		return false
+	}
+
+	for i, u := range l.usage {
+		if u > usage[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// comparable reports whether two labels at the same system can be weighed
+// against each other, which needs them to have arrived the same way when the
+// cost of leaving depends on that.
+func (s *search) comparable(a, b *label) bool {
+	if !s.net.fastest {
+		return true
+	}
+
+	if a.via == nil || b.via == nil {
+		return a.via == b.via
+	}
+
+	return *a.via == *b.via
+}
+
+func (l *label) same(r rank, jumps int, usage []int) bool {
+	if l.rank.compare(r) != 0 || l.jumps != jumps {
+		return false
+	}
+
+	for i := range l.usage {
+		if l.usage[i] != usage[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// findShortestRoutes searches out of start in order of increasing rank and
+// returns every best-ranked route to a target system, honoring the blocked
+// systems, budgets, and the query's jump and result limits. Partial routes are
+// pruned as soon as they exceed a budget, or when a cheaper label at the same
+// system was no worse in any other way. A start that is itself a target has no
+// route.
+func (f *Finder) findShortestRoutes(ctx context.Context, start int, s *search) ([]candidate, error) {
+	if s.targets.Has(start) {
+		return nil, errors.Wrap(ErrNoRoute, "start is a target")
+	}
+
+	labels := make([][]*label, len(f.graph))
+
+	origin := &label{system: start, usage: make([]int, len(s.budgets))}
+	if len(s.priorities) > 0 {
+		origin.tiers = make([]int, len(s.priorities))
+	}
+	labels[start] = []*label{origin}
+
+	queue := newCostQueue()
+	queue.push(origin)
+	var found []*label
+
+	for len(found) == 0 {
+		bucket, ok := queue.pop()
+		if !ok {
+			return nil, errors.Wrap(ErrNoRoute, "route impossible")
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Wrap(err, "search aborted")
+		}
+
+		for _, curr := range bucket {
+			if s.dominated(labels[curr.system], curr) {
+				continue
+			}
+
+			if s.targets.Has(curr.system) {
+				found = append(found, curr)
+				continue
+			}
+
+			s.net.each(curr.system, curr.via, func(e Edge, w int) {
+				if next := f.extend(labels, s, curr, e, w); next != nil {
+					queue.push(next)
+				}
+			})
+		}
+	}
+
+	routes := make([]candidate, 0, len(found))
+	for _, end := range found {
+		routes = appendRoutesTo(routes, end, s.limit)
+		if s.limit > 0 && len(routes) >= s.limit {
+			break
+		}
+	}
+
+	return routes, nil
+}
+
+// costQueue holds the labels waiting to be explored, best-ranked first.
+type costQueue []*label
+
+func newCostQueue() *costQueue {
+	return &costQueue{}
+}
+
+func (q *costQueue) push(l *label) {
+	heap.Push(q, l)
+}
+
+// pop removes and returns the best-ranked labels, all level with each other,
+// or false if there are none.
+func (q *costQueue) pop() ([]*label, bool) {
+	if q.Len() == 0 {
+		return nil, false
+	}
+
+	bucket := []*label{heap.Pop(q).(*label)}
+	for q.Len() > 0 && (*q)[0].rank.compare(bucket[0].rank) == 0 {
+		bucket = append(bucket, heap.Pop(q).(*label))
+	}
+
+	return bucket, true
+}
+
+func (q costQueue) Len() int            { return len(q) }
+func (q costQueue) Less(i, j int) bool  { return q[i].rank.compare(q[j].rank) < 0 }
+func (q costQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *costQueue) Push(x interface{}) { *q = append(*q, x.(*label)) }
+func (q *costQueue) Pop() interface{} {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
+
+// dominated reports whether some strictly better-ranked label at the same
+// system is no worse than l. Labels can be overtaken this way after they are
+// queued, when a better route to their system turns up later.
+func (s *search) dominated(others []*label, l *label) bool {
+	for _, o := range others {
+		if o.rank.compare(l.rank) < 0 && s.comparable(o, l) && o.dominates(l.rank, l.jumps, l.usage) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// extend follows edge e out of curr, returning the new label to explore or nil
+// if the edge leads nowhere useful.
+func (f *Finder) extend(labels [][]*label, s *search, curr *label, e Edge, w int) *label {
+	if s.blocked.Has(e.To) {
+		return nil
+	}
+
+	jumps := curr.jumps + 1
+	if s.maxJumps > 0 && jumps > s.maxJumps {
+		return nil
+	}
+
+	usage := make([]int, len(s.budgets))
+	for i, b := range s.budgets {
+		usage[i] = curr.usage[i]
+		if b.systems.Has(e.To) {
+			usage[i]++
+		}
+
+		if usage[i] > b.max { // over budget
+			return nil
+		}
+	}
+
+	r := rank{cost: curr.cost + w}
+	if len(s.priorities) > 0 {
+		r.tiers = make([]int, len(s.priorities))
+		for i, set := range s.priorities {
+			r.tiers[i] = curr.tiers[i]
+			if set.Has(e.To) {
+				r.tiers[i]++
+			}
+		}
+	}
+
+	via := &label{via: &e}
+	for _, l := range labels[e.To] {
+		if !s.comparable(l, via) {
+			continue
+		}
+
+		if l.same(r, jumps, usage) { // another best way in
+			l.preds = append(l.preds, link{from: curr, edge: e})
+			return nil
+		}
+
+		if l.rank.compare(r) < 0 && l.dominates(r, jumps, usage) { // already visited
+			return nil
+		}
+	}
+
+	next := &label{
+		system: e.To,
+		rank:   r,
+		jumps:  jumps,
+		usage:  usage,
+		preds:  []link{{from: curr, edge: e}},
+		via:    &e,
+	}
+	labels[e.To] = append(labels[e.To], next)
+
+	return next
+}
+
+// appendRoutesTo walks the predecessor labels back from end and appends every
+// route that leads there, stopping once limit routes have been collected.
+func appendRoutesTo(routes []candidate, end *label, limit int) []candidate {
+	systems := make([]int, 0, end.jumps+1)
+	edges := make([]Edge, 0, end.jumps)
+
+	var walk func(curr *label)
+	walk = func(curr *label) {
+		if limit > 0 && len(routes) >= limit {
+			return
+		}
+
+		systems = append(systems, curr.system)
+		defer func() { systems = systems[:len(systems)-1] }()
+
+		if len(curr.preds) == 0 {
+			c := candidate{
+				systems: make([]int, len(systems)),
+				edges:   make([]Edge, len(edges)),
+				rank:    end.rank,
+			}
+			for i, v := range systems {
+				c.systems[len(systems)-1-i] = v
+			}
+			for i, e := range edges {
+				c.edges[len(edges)-1-i] = e
+			}
+			routes = append(routes, c)
+			return
+		}
+
+		for _, p := range curr.preds {
+			edges = append(edges, p.edge)
+			walk(p.from)
+			edges = edges[:len(edges)-1]
+		}
+	}
+
+	walk(end)
+
+	return routes
+}
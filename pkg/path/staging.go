@@ -0,0 +1,160 @@
+package path
+
+import (
+	"context"
+	"sort"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/bitset"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/tagexpr"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+)
+
+// StagingQuery asks for the systems within Range jumps of the most target
+// systems. Candidates are limited to the systems carrying any of the candidate
+// tags or matching any of the candidate expressions, or every system if
+// neither is given. Limit caps the number of systems returned, 10 if unset.
+type StagingQuery struct {
+	Targets     []int
+	TargetTags  []int
+	TargetExprs []tagexpr.Expr
+
+	CandidateTags  []int
+	CandidateExprs []tagexpr.Expr
+
+	Range int
+	Constraints
+	Limit int
+}
+
+// Reach is a target within range of a staging system.
+type Reach struct {
+	System int
+	Jumps  int
+}
+
+// Staging is a candidate staging system and the targets it covers, nearest
+// first.
+type Staging struct {
+	System       int
+	Covered      []Reach
+	AverageJumps float64 // over the covered targets
+	Targets      int     // the number of targets considered
+}
+
+const defaultStagingLimit = 10
+
+func (q *StagingQuery) validate(numSystems int) error {
+	if len(q.Targets) == 0 && len(q.TargetTags) == 0 && len(q.TargetExprs) == 0 {
+		return errors.Wrap(ErrBadQuery, "no target systems, tags, or expressions")
+	}
+
+	for _, t := range q.Targets {
+		if t < 0 || t >= numSystems {
+			return errors.Wrap(ErrBadQuery, "unknown target system", "system", t)
+		}
+	}
+
+	if q.Range <= 0 {
+		return errors.Wrap(ErrBadQuery, "range must be at least one jump")
+	}
+
+	if q.Limit < 0 {
+		return errors.Wrap(ErrBadQuery, "negative limit")
+	}
+
+	return nil
+}
+
+func (f *Finder) candidateSet(q StagingQuery) bitset.Set {
+	candidates := bitset.New(len(f.graph))
+	if len(q.CandidateTags) == 0 && len(q.CandidateExprs) == 0 {
+		for sys := range f.graph {
+			candidates.Add(sys)
+		}
+		return candidates
+	}
+
+	for _, t := range q.CandidateTags {
+		candidates.Union(f.tagSet(t))
+	}
+
+	for _, e := range q.CandidateExprs {
+		candidates.Union(f.exprSet(e))
+	}
+
+	return candidates
+}
+
+// RankStaging ranks the candidate systems by how many targets they can reach
+// within range, then by the average jumps to those targets.
+func (f *Finder) RankStaging(ctx context.Context, q StagingQuery) ([]Staging, error) {
+	if err := q.validate(len(f.graph)); err != nil {
+		return nil, err
+	}
+
+	limit := q.Limit
+	if limit == 0 {
+		limit = defaultStagingLimit
+	}
+
+	targets := f.targetSet(Query{Targets: q.Targets, TargetTags: q.TargetTags, TargetExprs: q.TargetExprs})
+	numTargets := targets.Count()
+	if numTargets == 0 {
+		return nil, errors.Wrap(ErrBadQuery, "no systems match the targets")
+	}
+
+	net, blocked := f.constrained(q.Constraints, q.Targets...)
+
+	var found []Staging
+	for _, sys := range f.candidateSet(q).Members() {
+		if blocked.Has(sys) {
+			continue
+		}
+
+		m, err := f.jumpsFrom(ctx, sys, net, blocked, q.Range)
+		if err != nil {
+			return nil, err
+		}
+
+		s := Staging{System: sys, Targets: numTargets}
+		total := 0
+		for _, t := range targets.Members() {
+			if d := m.dist[t]; d != -1 {
+				s.Covered = append(s.Covered, Reach{System: t, Jumps: d})
+				total += d
+			}
+		}
+
+		if len(s.Covered) == 0 {
+			continue
+		}
+
+		s.AverageJumps = float64(total) / float64(len(s.Covered))
+		sort.SliceStable(s.Covered, func(i, j int) bool {
+			return s.Covered[i].Jumps < s.Covered[j].Jumps
+		})
+
+		found = append(found, s)
+	}
+
+	if len(found) == 0 {
+		return nil, errors.Wrap(ErrNoRoute, "no candidate within range of a target", "range", q.Range)
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		switch {
+		case len(found[i].Covered) != len(found[j].Covered):
+			return len(found[i].Covered) > len(found[j].Covered)
+		case found[i].AverageJumps != found[j].AverageJumps:
+			return found[i].AverageJumps < found[j].AverageJumps
+		default:
+			return found[i].System < found[j].System
+		}
+	})
+
+	if len(found) > limit {
+		found = found[:limit]
+	}
+
+	return found, nil
+}
@@ -0,0 +1,224 @@
+package path
+
+import (
+	"context"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/tagexpr"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+)
+
+// SweepQuery asks for a tour from Start that visits every target system, e.g.
+// every system in a constellation or region tag, in as few jumps as possible.
+// If Return is set, the tour ends back at Start.
+type SweepQuery struct {
+	Start       int
+	Targets     []int
+	TargetTags  []int
+	TargetExprs []tagexpr.Expr
+	Return      bool
+	Constraints
+}
+
+// Sweep is a tour of the target systems. Order is the order the targets are
+// visited in, though the route may pass through some of them earlier on the way
+// to others. Unreachable holds the targets the tour could not get to.
+type Sweep struct {
+	Order       []int
+	Route       Route
+	Unreachable []int
+}
+
+func (q *SweepQuery) validate(numSystems int) error {
+	if q.Start < 0 || q.Start >= numSystems {
+		return errors.Wrap(ErrBadQuery, "unknown start system", "system", q.Start)
+	}
+
+	if len(q.Targets) == 0 && len(q.TargetTags) == 0 && len(q.TargetExprs) == 0 {
+		return errors.Wrap(ErrBadQuery, "no target systems, tags, or expressions")
+	}
+
+	for _, t := range q.Targets {
+		if t < 0 || t >= numSystems {
+			return errors.Wrap(ErrBadQuery, "unknown target system", "system", t)
+		}
+	}
+
+	return nil
+}
+
+// tour is a visiting order over the nodes of a distance matrix, starting at
+// node 0.
+type tour struct {
+	dist   [][]int
+	order  []int
+	closed bool
+}
+
+// unreachableLeg stands in for the jumps between two systems with no route
+// between them, so the heuristics steer clear of the leg.
+const unreachableLeg = 1 << 20
+
+func (t *tour) leg(a, b int) int {
+	if d := t.dist[a][b]; d != -1 {
+		return d
+	}
+	return unreachableLeg
+}
+
+// nearestNeighbor builds the tour by always going to the closest node not yet
+// visited.
+func (t *tour) nearestNeighbor() {
+	n := len(t.dist)
+	visited := make([]bool, n)
+	visited[0] = true
+	t.order = []int{0}
+
+	for len(t.order) < n {
+		cur := t.order[len(t.order)-1]
+		best := -1
+		for j := 0; j < n; j++ {
+			if !visited[j] && (best == -1 || t.leg(cur, j) < t.leg(cur, best)) {
+				best = j
+			}
+		}
+
+		visited[best] = true
+		t.order = append(t.order, best)
+	}
+}
+
+// twoOpt reverses stretches of the tour while that makes it shorter. Jumps are
+// taken to cost the same both ways, so only the legs at either end of a
+// reversed stretch change.
+func (t *tour) twoOpt(ctx context.Context) error {
+	n := len(t.order)
+
+	for improved := true; improved; {
+		if err := ctx.Err(); err != nil {
+			return errors.Wrap(err, "search aborted")
+		}
+
+		improved = false
+		for i := 1; i < n-1; i++ {
+			for j := i + 1; j < n; j++ {
+				a, b, c := t.order[i-1], t.order[i], t.order[j]
+
+				before, after := t.leg(a, b), t.leg(a, c)
+				if e, ok := t.after(j); ok {
+					before += t.leg(c, e)
+					after += t.leg(b, e)
+				}
+
+				if after < before {
+					reverse(t.order[i : j+1])
+					improved = true
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// after returns the node following position i, wrapping around to the start if
+// the tour is closed.
+func (t *tour) after(i int) (int, bool) {
+	switch {
+	case i+1 < len(t.order):
+		return t.order[i+1], true
+	case t.closed:
+		return t.order[0], true
+	default:
+		return 0, false
+	}
+}
+
+func reverse(s []int) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// PlanSweep finds a short tour of the target systems from the query's start,
+// using the nearest neighbor heuristic improved by 2-opt.
+func (f *Finder) PlanSweep(ctx context.Context, q SweepQuery) (Sweep, error) {
+	if err := q.validate(len(f.graph)); err != nil {
+		return Sweep{}, err
+	}
+
+	targets := f.targetSet(Query{Targets: q.Targets, TargetTags: q.TargetTags, TargetExprs: q.TargetExprs})
+	targets.Remove(q.Start)
+
+	net, blocked := f.constrained(q.Constraints, append([]int{q.Start}, q.Targets...)...)
+
+	start, err := f.jumpsFrom(ctx, q.Start, net, blocked, 0)
+	if err != nil {
+		return Sweep{}, err
+	}
+
+	sweep := Sweep{}
+	nodes := []int{q.Start}
+	maps := []*jumpMap{start}
+	for _, t := range targets.Members() {
+		if start.dist[t] == -1 {
+			sweep.Unreachable = append(sweep.Unreachable, t)
+			continue
+		}
+
+		m, err := f.jumpsFrom(ctx, t, net, blocked, 0)
+		if err != nil {
+			return Sweep{}, err
+		}
+
+		nodes = append(nodes, t)
+		maps = append(maps, m)
+	}
+
+	if len(nodes) == 1 {
+		return Sweep{}, errors.Wrap(ErrNoRoute, "no target system can be reached")
+	}
+
+	t := &tour{
+		dist:   make([][]int, len(nodes)),
+		closed: q.Return,
+	}
+	for i, m := range maps {
+		t.dist[i] = make([]int, len(nodes))
+		for j, sys := range nodes {
+			t.dist[i][j] = m.dist[sys]
+		}
+	}
+
+	t.nearestNeighbor()
+	if err := t.twoOpt(ctx); err != nil {
+		return Sweep{}, err
+	}
+
+	stops := t.order[1:]
+	if q.Return {
+		stops = append(stops, 0)
+	}
+
+	c := candidate{systems: []int{q.Start}}
+	prev := 0
+	for _, i := range stops {
+		leg, ok := maps[prev].path(nodes[i])
+		if !ok {
+			return Sweep{}, errors.Wrap(ErrNoRoute, "no route between targets", "from", nodes[prev], "to", nodes[i])
+		}
+
+		c.systems = append(c.systems, leg.systems[1:]...)
+		c.edges = append(c.edges, leg.edges...)
+		c.cost += leg.cost
+		prev = i
+	}
+
+	for _, i := range t.order[1:] {
+		sweep.Order = append(sweep.Order, nodes[i])
+	}
+
+	sweep.Route = f.newRoute(c, nil, nil)
+	sweep.Route.ETA, sweep.Route.WarpAU = f.eta(c.edges, q.travel())
+
+	return sweep, nil
+}
@@ -0,0 +1,97 @@
+package path
+
+import (
+	"context"
+	stderr "errors"
+	"testing"
+	"time"
+)
+
+// TestFleetTravel checks the fleet searches time their routes with the
+// constraints' travel model rather than the default one.
+func TestFleetTravel(t *testing.T) {
+	f := newTestFinder()
+	ctx := context.Background()
+
+	slow := DefaultTravel
+	slow.JumpTime = time.Hour
+	c := Constraints{Travel: &slow}
+
+	tests := []struct {
+		name string
+		find func() ([]Route, error)
+	}{
+		{
+			name: "rendezvous",
+			find: func() ([]Route, error) {
+				found, err := f.FindRendezvous(ctx, RendezvousQuery{
+					Members:     []Member{{System: sysA}, {System: sysJ}},
+					Constraints: c,
+					Limit:       1,
+				})
+				if err != nil {
+					return nil, err
+				}
+				return found[0].Routes, nil
+			},
+		},
+		{
+			name: "roam",
+			find: func() ([]Route, error) {
+				roams, err := f.FindRoams(ctx, RoamQuery{Home: sysA, Systems: 3, Constraints: c, Limit: 1})
+				if err != nil {
+					return nil, err
+				}
+				return []Route{roams[0].Route}, nil
+			},
+		},
+		{
+			name: "sweep",
+			find: func() ([]Route, error) {
+				sweep, err := f.PlanSweep(ctx, SweepQuery{Start: sysA, Targets: []int{sysE, sysJ}, Constraints: c})
+				return []Route{sweep.Route}, err
+			},
+		},
+		{
+			name: "courier",
+			find: func() ([]Route, error) {
+				plan, err := f.PlanCourier(ctx, CourierQuery{
+					Start:       sysA,
+					Deliveries:  []Delivery{{Pickup: sysE, Dropoff: sysJ}},
+					Constraints: c,
+				})
+				return []Route{plan.Route}, err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes, err := tt.find()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, r := range routes {
+				if min := time.Duration(r.Jumps) * slow.JumpTime; r.ETA < min {
+					t.Errorf("route %v takes %v; want at least %v", r.Systems, r.ETA, min)
+				}
+			}
+		})
+	}
+}
+
+// TestFastestNoGatePositions checks the time objective fails outright on system
+// data generated without stargate positions, rather than guessing.
+func TestFastestNoGatePositions(t *testing.T) {
+	f := newTestFinder()
+	ctx := context.Background()
+
+	if _, err := f.Route(ctx, Query{Sources: []int{sysA}, Targets: []int{sysJ}, Objective: Fastest}); !stderr.Is(err, ErrNoCoordinates) {
+		t.Errorf("fastest route: got %v; want ErrNoCoordinates", err)
+	}
+
+	if _, err := f.Route(ctx, Query{Sources: []int{sysA}, Targets: []int{sysJ}}); err != nil {
+		t.Errorf("shortest route: unexpected error: %v", err)
+	}
+}
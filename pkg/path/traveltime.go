@@ -0,0 +1,149 @@
+package path
+
+import (
+	"math"
+	"time"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/system"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+)
+
+type RouteObjective int
+
+const (
+	Shortest RouteObjective = iota // fewest jumps
+	Fastest                        // least estimated travel time
+)
+
+func ParseRouteObjective(s string) (RouteObjective, error) {
+	switch s {
+	case "", "jumps":
+		return Shortest, nil
+	case "time":
+		return Fastest, nil
+	default:
+		return Shortest, errors.Wrap(ErrBadQuery, "unknown route objective", "objective", s)
+	}
+}
+
+// Travel describes how quickly a ship gets across a system: it sits in gate
+// cloak for GateCloak after arriving, aligns for AlignTime, warps at WarpSpeed
+// AU/s to the next gate, and spends JumpTime jumping through it. Warps between
+// gates with unknown positions, such as those to and from jump bridges, are
+// taken to be FallbackWarpAU long.
+type Travel struct {
+	AlignTime      time.Duration
+	WarpSpeed      float64
+	JumpTime       time.Duration
+	GateCloak      time.Duration
+	FallbackWarpAU float64
+}
+
+// DefaultTravel is roughly a cruiser, moving off the gate as soon as it lands.
+var DefaultTravel = Travel{
+	AlignTime:      6 * time.Second,
+	WarpSpeed:      3,
+	JumpTime:       10 * time.Second,
+	FallbackWarpAU: 15,
+}
+
+// warpDropout is the speed, in m/s, at which a ship drops out of warp.
+const warpDropout = 100.0
+
+// WarpTime is how long a warp of the given length takes, accelerating and
+// decelerating the way the game does.
+func (t Travel) WarpTime(meters float64) time.Duration {
+	if meters <= 0 || t.WarpSpeed <= 0 {
+		return 0
+	}
+
+	accel := t.WarpSpeed
+	decel := math.Min(t.WarpSpeed/3, 2)
+	maxSpeed := t.WarpSpeed * system.MetersPerAU
+
+	cruise := 0.0
+	if minDist := system.MetersPerAU + maxSpeed/decel; minDist > meters {
+		// too short to reach full speed
+		maxSpeed = meters * accel * decel / (accel + decel)
+	} else {
+		cruise = (meters - minDist) / maxSpeed
+	}
+
+	secs := cruise + math.Log(maxSpeed/accel)/accel + math.Log(maxSpeed/warpDropout)/decel
+	if secs < 0 {
+		secs = 0
+	}
+
+	return time.Duration(secs * float64(time.Second))
+}
+
+// hop is the time taken to leave a system through e, having arrived along via.
+// A route is taken to start on its first gate, so the first hop is only the
+// jump itself.
+func (t Travel) hop(f *Finder, via *Edge, e Edge) time.Duration {
+	if via == nil {
+		return t.JumpTime
+	}
+
+	dist, ok := f.warpDistance(*via, e)
+	if !ok {
+		dist = t.FallbackWarpAU * system.MetersPerAU
+	}
+
+	return t.GateCloak + t.AlignTime.Round(time.Second) + t.WarpTime(dist) + t.JumpTime
+}
+
+// SetGatePositions gives the in-system positions of stargates, by gate ID. This
+// is not safe to call while searches are running.
+func (f *Finder) SetGatePositions(positions map[int64]system.Position) {
+	f.gatePositions = positions
+}
+
+// HasGatePositions reports whether any stargate positions are known. Without
+// them every warp is FallbackWarpAU long, so travel times mean little.
+func (f *Finder) HasGatePositions() bool {
+	return len(f.gatePositions) > 0
+}
+
+// warpDistance is the distance in meters from where via lands to where e
+// leaves from, if both gates' positions are known.
+func (f *Finder) warpDistance(via, e Edge) (float64, bool) {
+	if via.ToGate == 0 || e.Gate == 0 {
+		return 0, false
+	}
+
+	from, ok := f.gatePositions[via.ToGate]
+	if !ok {
+		return 0, false
+	}
+
+	to, ok := f.gatePositions[e.Gate]
+	if !ok {
+		return 0, false
+	}
+
+	return from.Distance(to), true
+}
+
+// eta estimates how long a route takes to fly, and how far it warps in AU.
+func (f *Finder) eta(edges []Edge, t Travel) (time.Duration, float64) {
+	var total time.Duration
+	var warpAU float64
+
+	for i, e := range edges {
+		if i == 0 {
+			total += t.hop(f, nil, e)
+			continue
+		}
+
+		total += t.hop(f, &edges[i-1], e)
+
+		dist, ok := f.warpDistance(edges[i-1], e)
+		if !ok {
+			dist = t.FallbackWarpAU * system.MetersPerAU
+		}
+		warpAU += dist / system.MetersPerAU
+	}
+
+	return total, warpAU
+}
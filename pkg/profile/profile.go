@@ -0,0 +1,111 @@
+// Package profile loads named ship profiles, which bundle the routing options
+// that suit a hull so requests do not have to spell them all out.
+package profile
+
+import (
+	"os"
+	"sort"
+	"time"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/path"
+	"github.com/gsmcwhirter/go-util/v7/deferutil"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Profile holds defaults for route requests. Zero values leave the request's
+// own settings alone; tag and expression lists are added to the request's.
+type Profile struct {
+	AvoidTags      []string       `yaml:"avoid_tags"`
+	AvoidExprs     []string       `yaml:"avoid_exprs"`
+	PreferNotTags  []string       `yaml:"prefer_not_tags"`
+	PreferNotExprs []string       `yaml:"prefer_not_exprs"`
+	MaxTagJumps    map[string]int `yaml:"max_tag_jumps"`
+
+	// cost weights
+	Objective    string  `yaml:"objective"`
+	UseBridges   bool    `yaml:"use_bridges"`
+	BridgeCost   int     `yaml:"bridge_cost"`
+	Intel        string  `yaml:"intel"`
+	IntelPenalty int     `yaml:"intel_penalty"`
+	DangerWeight float64 `yaml:"danger_weight"`
+	MaxDanger    float64 `yaml:"max_danger"`
+
+	// what wormholes the ship fits through
+	ShipSize string `yaml:"ship_size"`
+	ShipMass int64  `yaml:"ship_mass"`
+
+	// travel time
+	AlignTime time.Duration `yaml:"align_time"`
+	WarpSpeed float64       `yaml:"warp_speed"`
+	JumpTime  time.Duration `yaml:"jump_time"`
+	GateCloak time.Duration `yaml:"gate_cloak"`
+
+	// jump drive
+	ShipClass   string  `yaml:"ship_class"`
+	JumpRangeLY float64 `yaml:"jump_range_ly"`
+}
+
+// Travel applies the profile's travel settings to t.
+func (p Profile) Travel(t path.Travel) path.Travel {
+	if p.AlignTime != 0 {
+		t.AlignTime = p.AlignTime
+	}
+	if p.WarpSpeed != 0 {
+		t.WarpSpeed = p.WarpSpeed
+	}
+	if p.JumpTime != 0 {
+		t.JumpTime = p.JumpTime
+	}
+	if p.GateCloak != 0 {
+		t.GateCloak = p.GateCloak
+	}
+
+	return t
+}
+
+type File struct {
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+var ErrUnknownProfile = errors.New("unknown profile")
+
+// Profiles are the named profiles from a config file.
+type Profiles map[string]Profile
+
+func Load(path string) (Profiles, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open profile file", "path", path)
+	}
+	defer deferutil.CheckDefer(f.Close)
+
+	contents := File{}
+
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&contents); err != nil {
+		return nil, errors.Wrap(err, "could not yaml decode profile file", "path", path)
+	}
+
+	return contents.Profiles, nil
+}
+
+func (ps Profiles) Get(name string) (Profile, error) {
+	p, ok := ps[name]
+	if !ok {
+		return Profile{}, errors.Wrap(ErrUnknownProfile, "no such profile", "profile", name, "known", ps.Names())
+	}
+
+	return p, nil
+}
+
+func (ps Profiles) Names() []string {
+	names := make([]string, 0, len(ps))
+	for name := range ps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
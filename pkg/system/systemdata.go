@@ -1,11 +1,62 @@
 package system
 
+import "math"
+
 type Data struct {
-	ID            int      `json:"id"`
-	Name          string   `json:"name"`
-	Constellation string   `json:"constellation"`
-	Region        string   `json:"region"`
-	Destinations  []string `json:"destinations"`
-	SecStatus     string   `json:"sec_status"`
-	Tags          []string `json:"tags"`
+	ID            int       `json:"id"`
+	Name          string    `json:"name"`
+	Constellation string    `json:"constellation"`
+	Region        string    `json:"region"`
+	Destinations  []string  `json:"destinations"`
+	SecStatus     string    `json:"sec_status"`
+	Tags          []string  `json:"tags"`
+	Position      *Position `json:"position,omitempty" yaml:",omitempty"`
+	Gates         []Gate    `json:"gates,omitempty" yaml:",omitempty"`
+}
+
+// noCynoRegions are the regions where cynosural fields cannot be lit, whatever
+// their security: Pochven and the Jove regions.
+var noCynoRegions = map[string]bool{
+	"Pochven": true,
+	"A821-A":  true,
+	"J7HZ-F":  true,
+	"UUA-F4":  true,
+}
+
+// NoCyno reports whether cynosural fields cannot be lit in the system because
+// of its region.
+func (d Data) NoCyno() bool {
+	return noCynoRegions[d.Region]
+}
+
+// Gate is a stargate in a system. DestinationGate is the ID of the gate it
+// lands on in the Destination system.
+type Gate struct {
+	ID              int64     `json:"id"`
+	Name            string    `json:"name"`
+	Destination     string    `json:"destination"`
+	DestinationGate int64     `json:"destination_gate"`
+	Position        *Position `json:"position,omitempty" yaml:",omitempty"` // within the system
+}
+
+// MetersPerLY is the length of a light year in the units of the SDE coordinates.
+const MetersPerLY = 9460730472580800.0
+
+// MetersPerAU is the length of an astronomical unit, which warp speeds use.
+const MetersPerAU = 149597870700.0
+
+// Position is the location of a system's center in universe coordinates (meters).
+type Position struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+func (p Position) Distance(o Position) float64 {
+	dx, dy, dz := p.X-o.X, p.Y-o.Y, p.Z-o.Z
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
+
+func (p Position) DistanceLY(o Position) float64 {
+	return p.Distance(o) / MetersPerLY
 }
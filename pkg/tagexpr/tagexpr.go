@@ -0,0 +1,205 @@
+package tagexpr
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/bitset"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+)
+
+// Expr is a boolean expression over the tags of a system, such as
+// `low & !Placid` or `(null | trig-minor) & Delve`. `&` binds tighter than `|`,
+// and tag names containing spaces or operators can be double-quoted.
+type Expr interface {
+	Match(tags bitset.Set) bool
+	String() string
+}
+
+var (
+	ErrSyntax     = errors.New("tag expression syntax error")
+	ErrUnknownTag = errors.New("unknown tag")
+)
+
+type tagExpr struct {
+	id   int
+	name string
+}
+
+func (e tagExpr) Match(tags bitset.Set) bool { return tags.Has(e.id) }
+func (e tagExpr) String() string {
+	if strings.ContainsAny(e.name, " \t&|!()\"") {
+		return `"` + e.name + `"`
+	}
+
+	return e.name
+}
+
+type notExpr struct {
+	x Expr
+}
+
+func (e notExpr) Match(tags bitset.Set) bool { return !e.x.Match(tags) }
+func (e notExpr) String() string             { return "!" + e.x.String() }
+
+type andExpr struct {
+	l, r Expr
+}
+
+func (e andExpr) Match(tags bitset.Set) bool { return e.l.Match(tags) && e.r.Match(tags) }
+func (e andExpr) String() string             { return "(" + e.l.String() + " & " + e.r.String() + ")" }
+
+type orExpr struct {
+	l, r Expr
+}
+
+func (e orExpr) Match(tags bitset.Set) bool { return e.l.Match(tags) || e.r.Match(tags) }
+func (e orExpr) String() string             { return "(" + e.l.String() + " | " + e.r.String() + ")" }
+
+// Tag returns an expression matching systems that carry a single tag.
+func Tag(id int, name string) Expr {
+	return tagExpr{id: id, name: name}
+}
+
+// Parse compiles an expression, resolving tag names to ids with lookup.
+func Parse(s string, lookup func(name string) (int, bool)) (Expr, error) {
+	p := &parser{
+		input:  s,
+		lookup: lookup,
+	}
+
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return nil, errors.Wrap(ErrSyntax, "unexpected input", "pos", p.pos, "expr", s)
+	}
+
+	return e, nil
+}
+
+type parser struct {
+	input  string
+	pos    int
+	lookup func(string) (int, bool)
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *parser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+
+	return p.input[p.pos]
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == '|' {
+		p.pos++
+
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		l = orExpr{l: l, r: r}
+	}
+
+	return l, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == '&' {
+		p.pos++
+
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		l = andExpr{l: l, r: r}
+	}
+
+	return l, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	switch p.peek() {
+	case 0:
+		return nil, errors.Wrap(ErrSyntax, "unexpected end of expression", "expr", p.input)
+	case '!':
+		p.pos++
+
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return notExpr{x: x}, nil
+	case '(':
+		p.pos++
+
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.peek() != ')' {
+			return nil, errors.Wrap(ErrSyntax, "missing closing parenthesis", "pos", p.pos, "expr", p.input)
+		}
+		p.pos++
+
+		return x, nil
+	case '"':
+		p.pos++
+
+		end := strings.IndexByte(p.input[p.pos:], '"')
+		if end < 0 {
+			return nil, errors.Wrap(ErrSyntax, "unterminated quote", "pos", p.pos, "expr", p.input)
+		}
+
+		name := p.input[p.pos : p.pos+end]
+		p.pos += end + 1
+
+		return p.tag(name)
+	}
+
+	start := p.pos
+	for p.pos < len(p.input) && !strings.ContainsRune(" \t\r\n&|!()\"", rune(p.input[p.pos])) {
+		p.pos++
+	}
+
+	if p.pos == start {
+		return nil, errors.Wrap(ErrSyntax, "expected a tag name", "pos", p.pos, "expr", p.input)
+	}
+
+	return p.tag(p.input[start:p.pos])
+}
+
+func (p *parser) tag(name string) (Expr, error) {
+	id, ok := p.lookup(name)
+	if !ok {
+		return nil, errors.Wrap(ErrUnknownTag, "could not parse expression", "tag", name)
+	}
+
+	return Tag(id, name), nil
+}
@@ -0,0 +1,133 @@
+package tagexpr
+
+import (
+	stderr "errors"
+	"testing"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/bitset"
+)
+
+var testTags = map[string]int{
+	"a":          0,
+	"b":          1,
+	"c":          2,
+	"trig-minor": 3,
+	"a & b":      4,
+}
+
+func lookup(name string) (int, bool) {
+	id, ok := testTags[name]
+	return id, ok
+}
+
+func tags(ids ...int) bitset.Set {
+	s := bitset.New(len(testTags))
+	for _, id := range ids {
+		s.Add(id)
+	}
+
+	return s
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    string
+		match   []bitset.Set
+		noMatch []bitset.Set
+	}{
+		{
+			expr:    "a | b & c",
+			want:    "(a | (b & c))",
+			match:   []bitset.Set{tags(0), tags(1, 2)},
+			noMatch: []bitset.Set{tags(1), tags(2)},
+		},
+		{
+			expr:    "a & b | c",
+			want:    "((a & b) | c)",
+			match:   []bitset.Set{tags(0, 1), tags(2)},
+			noMatch: []bitset.Set{tags(0), tags(1)},
+		},
+		{
+			expr:    "!a & b",
+			want:    "(!a & b)",
+			match:   []bitset.Set{tags(1)},
+			noMatch: []bitset.Set{tags(0, 1), tags()},
+		},
+		{
+			expr:    "!(a & b)",
+			want:    "!(a & b)",
+			match:   []bitset.Set{tags(0), tags()},
+			noMatch: []bitset.Set{tags(0, 1)},
+		},
+		{
+			expr:    "!!a",
+			want:    "!!a",
+			match:   []bitset.Set{tags(0)},
+			noMatch: []bitset.Set{tags()},
+		},
+		{
+			expr:    `"a & b" | trig-minor`,
+			want:    `("a & b" | trig-minor)`,
+			match:   []bitset.Set{tags(4), tags(3)},
+			noMatch: []bitset.Set{tags(0, 1)},
+		},
+		{
+			expr:    "  ( a|b )&c ",
+			want:    "((a | b) & c)",
+			match:   []bitset.Set{tags(0, 2), tags(1, 2)},
+			noMatch: []bitset.Set{tags(0, 1)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := Parse(tt.expr, lookup)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := e.String(); got != tt.want {
+				t.Errorf("got %s; want %s", got, tt.want)
+			}
+
+			for _, s := range tt.match {
+				if !e.Match(s) {
+					t.Errorf("did not match %v", s.Members())
+				}
+			}
+
+			for _, s := range tt.noMatch {
+				if e.Match(s) {
+					t.Errorf("matched %v", s.Members())
+				}
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want error
+	}{
+		{expr: "", want: ErrSyntax},
+		{expr: "a &", want: ErrSyntax},
+		{expr: `"a & b`, want: ErrSyntax},
+		{expr: "(a | b", want: ErrSyntax},
+		{expr: "a | b)", want: ErrSyntax},
+		{expr: "a b", want: ErrSyntax},
+		{expr: "a & )", want: ErrSyntax},
+		{expr: "a | placid", want: ErrUnknownTag},
+		{expr: `"a &b"`, want: ErrUnknownTag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := Parse(tt.expr, lookup)
+			if !stderr.Is(err, tt.want) {
+				t.Errorf("got %v, %v; want %v", e, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package universe
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/bridge"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/path"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/system"
+	"github.com/gsmcwhirter/eve-route-finder/pkg/tagexpr"
+	"github.com/gsmcwhirter/go-util/v7/deferutil"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnknownSystem = errors.New("unknown system")
+	ErrUnknownTag    = errors.New("unknown tag")
+	ErrUnknownGate   = errors.New("unknown gate")
+)
+
+// Universe is the system data loaded from a graphmaker file, with a path
+// finder over it and lookups between the names people type and the IDs the
+// finder uses. System and gate names match regardless of case; tag names must
+// match exactly.
+type Universe struct {
+	Finder *path.Finder
+
+	data     []system.Data // by system ID
+	systems  map[string]int
+	tags     map[string]int
+	tagNames []string         // by tag ID
+	gates    map[string]int64 // "system:destination" to gate ID
+}
+
+type dataContents struct {
+	SystemData []system.Data
+}
+
+// Load reads a system data file written by graphmaker.
+func Load(file string) (*Universe, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open system data", "path", file)
+	}
+	defer deferutil.CheckDefer(f.Close)
+
+	contents := dataContents{}
+	if err := yaml.NewDecoder(f).Decode(&contents); err != nil {
+		return nil, errors.Wrap(err, "could not yaml decode system data", "path", file)
+	}
+
+	return New(contents.SystemData)
+}
+
+// New indexes the systems, whose IDs must run from 0, and builds a path finder
+// over their stargates.
+func New(data []system.Data) (*Universe, error) {
+	u := &Universe{
+		data:    make([]system.Data, len(data)),
+		systems: map[string]int{},
+		tags:    map[string]int{},
+		gates:   map[string]int64{},
+	}
+
+	for _, sd := range data {
+		if sd.ID < 0 || sd.ID >= len(data) {
+			return nil, errors.Wrap(ErrUnknownSystem, "system id out of range", "system", sd.Name, "id", sd.ID)
+		}
+
+		u.data[sd.ID] = sd
+		u.systems[strings.ToLower(sd.Name)] = sd.ID
+
+		for _, t := range sd.Tags {
+			if _, ok := u.tags[t]; !ok {
+				u.tags[t] = len(u.tagNames)
+				u.tagNames = append(u.tagNames, t)
+			}
+		}
+	}
+
+	graph := make([][]int, len(data))
+	graphTags := make([][]int, len(data))
+	secStatus := make([]string, len(data))
+	positions := make([]*system.Position, len(data))
+	var noCyno []int
+
+	for _, sd := range u.data {
+		var err error
+		if graph[sd.ID], err = u.SystemIDs(sd.Destinations); err != nil {
+			return nil, errors.Wrap(err, "bad destination", "system", sd.Name)
+		}
+
+		graphTags[sd.ID] = make([]int, len(sd.Tags))
+		for i, t := range sd.Tags {
+			graphTags[sd.ID][i] = u.tags[t]
+		}
+
+		secStatus[sd.ID] = sd.SecStatus
+		positions[sd.ID] = sd.Position
+
+		if sd.NoCyno() {
+			noCyno = append(noCyno, sd.ID)
+		}
+	}
+
+	u.Finder = path.NewFinder(graph, graphTags, secStatus, positions)
+	u.Finder.SetNoCyno(noCyno)
+
+	// name the stargates and place them, when the data has them
+	var gateEdges []path.Edge
+	gatePositions := map[int64]system.Position{}
+	for _, sd := range u.data {
+		for _, g := range sd.Gates {
+			if g.Position != nil {
+				gatePositions[g.ID] = *g.Position
+			}
+
+			to, ok := u.SystemID(g.Destination)
+			if !ok {
+				continue
+			}
+
+			u.gates[strings.ToLower(sd.Name+":"+g.Destination)] = g.ID
+			gateEdges = append(gateEdges, path.Edge{
+				From:   sd.ID,
+				To:     to,
+				Kind:   path.Stargate,
+				Label:  g.Name,
+				Gate:   g.ID,
+				ToGate: g.DestinationGate,
+			})
+		}
+	}
+	u.Finder.SetGates(gateEdges)
+	u.Finder.SetGatePositions(gatePositions)
+
+	return u, nil
+}
+
+// LoadBridges reads a jump bridge file and gives the finder its bridges, both
+// ways.
+func (u *Universe) LoadBridges(file string) error {
+	bridges, err := bridge.Load(file)
+	if err != nil {
+		return err
+	}
+
+	edges := make([]path.Edge, 0, 2*len(bridges))
+	for _, b := range bridges {
+		ends, err := u.SystemIDs([]string{b.From, b.To})
+		if err != nil {
+			return errors.Wrap(err, "bad bridge", "owner", b.Owner)
+		}
+
+		edges = append(edges,
+			path.Edge{From: ends[0], To: ends[1], Kind: path.JumpBridge, Label: b.Owner},
+			path.Edge{From: ends[1], To: ends[0], Kind: path.JumpBridge, Label: b.Owner},
+		)
+	}
+
+	u.Finder.SetBridges(edges)
+
+	return nil
+}
+
+// Systems returns every system, by ID.
+func (u *Universe) Systems() []system.Data {
+	return u.data
+}
+
+// System returns the data for a system ID.
+func (u *Universe) System(id int) system.Data {
+	return u.data[id]
+}
+
+// SystemID looks up a system name, ignoring case.
+func (u *Universe) SystemID(name string) (int, bool) {
+	id, ok := u.systems[strings.ToLower(name)]
+	return id, ok
+}
+
+// SystemIDs looks up system names, failing on any it does not know.
+func (u *Universe) SystemIDs(names []string) ([]int, error) {
+	ids := make([]int, len(names))
+	for i, name := range names {
+		id, ok := u.SystemID(name)
+		if !ok {
+			return nil, errors.Wrap(ErrUnknownSystem, "could not look up system", "name", name)
+		}
+		ids[i] = id
+	}
+
+	return ids, nil
+}
+
+func (u *Universe) SystemName(id int) string {
+	return u.data[id].Name
+}
+
+func (u *Universe) SystemNames(ids []int) []string {
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = u.data[id].Name
+	}
+
+	return names
+}
+
+// Tags returns every tag name, by ID.
+func (u *Universe) Tags() []string {
+	return u.tagNames
+}
+
+// TagID looks up a tag name; it fits tagexpr.Parse.
+func (u *Universe) TagID(name string) (int, bool) {
+	id, ok := u.tags[name]
+	return id, ok
+}
+
+// TagIDs looks up tag names, failing on any it does not know.
+func (u *Universe) TagIDs(names []string) ([]int, error) {
+	ids := make([]int, len(names))
+	for i, name := range names {
+		id, ok := u.TagID(name)
+		if !ok {
+			return nil, errors.Wrap(ErrUnknownTag, "could not look up tag", "name", name)
+		}
+		ids[i] = id
+	}
+
+	return ids, nil
+}
+
+func (u *Universe) TagNames(ids []int) []string {
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = u.tagNames[id]
+	}
+
+	return names
+}
+
+// ParseExprs parses tag expressions against the universe's tags.
+func (u *Universe) ParseExprs(exprs []string) ([]tagexpr.Expr, error) {
+	parsed := make([]tagexpr.Expr, len(exprs))
+	for i, e := range exprs {
+		var err error
+		if parsed[i], err = tagexpr.Parse(e, u.TagID); err != nil {
+			return nil, err
+		}
+	}
+
+	return parsed, nil
+}
+
+// ExprNames renders expressions back to text.
+func ExprNames(exprs []tagexpr.Expr) []string {
+	names := make([]string, len(exprs))
+	for i, e := range exprs {
+		names[i] = e.String()
+	}
+
+	return names
+}
+
+// GateIDs resolves gates given as "system:destination" names or as IDs.
+func (u *Universe) GateIDs(names []string) ([]int64, error) {
+	ids := make([]int64, len(names))
+	for i, name := range names {
+		if id, err := strconv.ParseInt(name, 10, 64); err == nil {
+			ids[i] = id
+			continue
+		}
+
+		id, ok := u.gates[strings.ToLower(name)]
+		if !ok {
+			return nil, errors.Wrap(ErrUnknownGate, "could not look up gate (use system:destination or a gate ID)", "name", name)
+		}
+		ids[i] = id
+	}
+
+	return ids, nil
+}
+
+// Budgets turns per-tag jump limits into search budgets.
+func (u *Universe) Budgets(maxTagJumps map[string]int) ([]path.Budget, error) {
+	budgets := make([]path.Budget, 0, len(maxTagJumps))
+	for name, max := range maxTagJumps {
+		id, ok := u.TagID(name)
+		if !ok {
+			return nil, errors.Wrap(ErrUnknownTag, "could not look up tag", "name", name)
+		}
+
+		budgets = append(budgets, path.Budget{Tag: id, Max: max})
+	}
+
+	return budgets, nil
+}
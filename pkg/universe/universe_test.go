@@ -0,0 +1,60 @@
+package universe
+
+import (
+	stderr "errors"
+	"reflect"
+	"testing"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/system"
+)
+
+func newTestUniverse(t *testing.T) *Universe {
+	t.Helper()
+
+	u, err := New([]system.Data{
+		{ID: 0, Name: "Jita", SecStatus: "high", Tags: []string{"high", "TheForge"}, Destinations: []string{"Perimeter"}},
+		{ID: 1, Name: "Perimeter", SecStatus: "high", Tags: []string{"high", "TheForge"}, Destinations: []string{"Jita"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return u
+}
+
+func TestLookups(t *testing.T) {
+	u := newTestUniverse(t)
+
+	ids, err := u.SystemIDs([]string{"jita", "PERIMETER"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []int{0, 1}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("got %v; want %v", ids, want)
+	}
+
+	if _, err := u.SystemIDs([]string{"Jita", "Amarr"}); !stderr.Is(err, ErrUnknownSystem) {
+		t.Errorf("got %v; want ErrUnknownSystem", err)
+	}
+
+	if _, err := u.TagIDs([]string{"theforge"}); !stderr.Is(err, ErrUnknownTag) {
+		t.Errorf("got %v; want ErrUnknownTag", err)
+	}
+
+	if _, err := u.Budgets(map[string]int{"low": 1}); !stderr.Is(err, ErrUnknownTag) {
+		t.Errorf("got %v; want ErrUnknownTag", err)
+	}
+
+	if _, err := u.ParseExprs([]string{"high & low"}); err == nil {
+		t.Error("parsed an expression with an unknown tag")
+	}
+}
+
+func TestNewUnknownDestination(t *testing.T) {
+	_, err := New([]system.Data{
+		{ID: 0, Name: "Jita", Destinations: []string{"Nowhere"}},
+	})
+	if !stderr.Is(err, ErrUnknownSystem) {
+		t.Errorf("got %v; want ErrUnknownSystem", err)
+	}
+}
@@ -0,0 +1,205 @@
+package wormhole
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gsmcwhirter/eve-route-finder/pkg/filestore"
+	"github.com/gsmcwhirter/go-util/v7/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Size is the largest class of ship a wormhole lets through.
+type Size int
+
+const (
+	AnySize   Size = iota
+	Small          // frigates and destroyers
+	Medium         // up to battlecruisers
+	Large          // up to battleships
+	VeryLarge      // freighters and industrial command ships
+	Capital
+)
+
+var sizeNames = map[Size]string{
+	AnySize:   "",
+	Small:     "small",
+	Medium:    "medium",
+	Large:     "large",
+	VeryLarge: "very-large",
+	Capital:   "capital",
+}
+
+func (s Size) String() string {
+	return sizeNames[s]
+}
+
+var ErrBadWormhole = errors.New("bad wormhole")
+
+func ParseSize(s string) (Size, error) {
+	for size, name := range sizeNames {
+		if name == s {
+			return size, nil
+		}
+	}
+
+	return AnySize, errors.Wrap(ErrBadWormhole, "unknown ship size", "size", s)
+}
+
+func (s Size) MarshalYAML() (interface{}, error) {
+	return s.String(), nil
+}
+
+func (s *Size) UnmarshalYAML(value *yaml.Node) error {
+	size, err := ParseSize(value.Value)
+	if err != nil {
+		return err
+	}
+
+	*s = size
+
+	return nil
+}
+
+// Wormhole is a temporary two-way connection between two systems, such as a
+// Thera or Turnur connection or a hole in a scanned chain. MaxShipSize and
+// MaxShipMass are left zero when the hole lets anything through.
+type Wormhole struct {
+	ID          string    `yaml:"id"`
+	From        string    `yaml:"from"`
+	To          string    `yaml:"to"`
+	Expires     time.Time `yaml:"expires"`
+	MaxShipSize Size      `yaml:"max_ship_size,omitempty"`
+	MaxShipMass int64     `yaml:"max_ship_mass,omitempty"` // kg
+	Note        string    `yaml:"note,omitempty"`
+}
+
+// Allows reports whether a ship of the given size and mass fits through the
+// wormhole. A zero size or mass is not checked.
+func (w Wormhole) Allows(size Size, mass int64) bool {
+	if w.MaxShipSize != AnySize && size > w.MaxShipSize {
+		return false
+	}
+
+	if w.MaxShipMass > 0 && mass > w.MaxShipMass {
+		return false
+	}
+
+	return true
+}
+
+func (w Wormhole) Remaining(now time.Time) time.Duration {
+	return w.Expires.Sub(now)
+}
+
+// RecordID and Expiry let wormholes be kept in a filestore.Store.
+func (w Wormhole) RecordID() string  { return w.ID }
+func (w Wormhole) Expiry() time.Time { return w.Expires }
+
+type File struct {
+	Wormholes []Wormhole `yaml:"wormholes"`
+}
+
+func (f *File) Records() []filestore.Record {
+	records := make([]filestore.Record, len(f.Wormholes))
+	for i, w := range f.Wormholes {
+		records[i] = w
+	}
+
+	return records
+}
+
+func (f *File) SetRecords(records []filestore.Record) {
+	f.Wormholes = make([]Wormhole, len(records))
+	for i, r := range records {
+		f.Wormholes[i] = r.(Wormhole)
+	}
+}
+
+// Store holds the known wormholes. Expired wormholes are left out of Live and
+// dropped on the next change. If it has a path, every change is written back
+// to that file.
+type Store struct {
+	holes *filestore.Store
+}
+
+// NewStore creates a store backed by the file at path, loading any wormholes
+// already in it. An empty path keeps the store in memory only.
+func NewStore(path string) (*Store, error) {
+	holes, err := filestore.NewStore(path, &File{})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load wormhole file")
+	}
+
+	return &Store{holes: holes}, nil
+}
+
+// Add registers a wormhole, giving it an ID if it has none, and returns it as
+// stored.
+func (s *Store) Add(w Wormhole) (Wormhole, error) {
+	if w.From == "" || w.To == "" {
+		return w, errors.Wrap(ErrBadWormhole, "wormhole is missing a system")
+	}
+
+	if w.From == w.To {
+		return w, errors.Wrap(ErrBadWormhole, "wormhole leads back to its own system", "system", w.From)
+	}
+
+	if !w.Expires.After(s.holes.Now()) {
+		return w, errors.Wrap(ErrBadWormhole, "wormhole has already expired")
+	}
+
+	if w.ID == "" {
+		id, err := filestore.NewID()
+		if err != nil {
+			return w, err
+		}
+		w.ID = id
+	}
+
+	if err := s.holes.Put(w); err != nil {
+		return w, errors.Wrap(err, "could not save wormholes")
+	}
+
+	return w, nil
+}
+
+// Remove forgets a wormhole, e.g. once it has collapsed early. It reports
+// whether the wormhole was known.
+func (s *Store) Remove(id string) (bool, error) {
+	ok, err := s.holes.Remove(id)
+	if err != nil {
+		return ok, errors.Wrap(err, "could not save wormholes")
+	}
+
+	return ok, nil
+}
+
+// Live returns the wormholes that have not yet expired, soonest to expire
+// first.
+func (s *Store) Live() []Wormhole {
+	records := s.holes.Live()
+
+	live := make([]Wormhole, len(records))
+	for i, r := range records {
+		live[i] = r.(Wormhole)
+	}
+
+	sort.Slice(live, func(i, j int) bool {
+		if !live[i].Expires.Equal(live[j].Expires) {
+			return live[i].Expires.Before(live[j].Expires)
+		}
+		return live[i].ID < live[j].ID
+	})
+
+	return live
+}
+
+func (s *Store) Get(id string) (Wormhole, bool) {
+	r, ok := s.holes.Get(id)
+	if !ok {
+		return Wormhole{}, false
+	}
+
+	return r.(Wormhole), true
+}